@@ -0,0 +1,315 @@
+package handler
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	errs "github.com/vsantosalmeida/csv-parser/pkg/errors"
+	"github.com/vsantosalmeida/csv-parser/usecase/csv"
+	"github.com/vsantosalmeida/csv-parser/usecase/job"
+)
+
+const (
+	// defaultFileName is the name of a raw upload without the name query param.
+	defaultFileName = "roster.csv"
+	// defaultMaxUploadSize is the max size of an uploaded file, 32MB.
+	defaultMaxUploadSize = 32 << 20
+)
+
+// errUploadTooLarge is returned by the request body of an upload larger than Config.MaxUploadSize.
+var errUploadTooLarge = stderrors.New("the upload is too large")
+
+// Config configures the jobs handler.
+type Config struct {
+	// MaxUploadSize is the max size in bytes of an uploaded file, 32MB when 0.
+	MaxUploadSize int64
+}
+
+type jobHandler struct {
+	service *job.Service
+	config  Config
+}
+
+// NewJobHandler returns the http.Handler of the jobs API:
+//
+//	POST   /jobs                  queues a file sent as multipart or as the raw body, returning the job
+//	GET    /jobs                  returns all the jobs, the most recent first
+//	GET    /jobs/{id}             returns the job with its state, progress and run summary
+//	DELETE /jobs/{id}             cancels a queued or running job
+//	GET    /jobs/{id}/employees   returns the employees of a finished job
+//	GET    /jobs/{id}/bad-data    returns the bad data of a finished job by file
+//
+// The multipart form has the "file" and the "pattern" with the csv.FilePattern JSON, a raw body has the
+// "pattern" query param or the X-File-Pattern header, and the "name" query param with the file name.
+func NewJobHandler(service *job.Service, config Config) http.Handler {
+	if config.MaxUploadSize <= 0 {
+		config.MaxUploadSize = defaultMaxUploadSize
+	}
+
+	h := &jobHandler{
+		service: service,
+		config:  config,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs", h.handleJobs)
+	mux.HandleFunc("/jobs/", h.handleJob)
+
+	return mux
+}
+
+func (h *jobHandler) handleJobs(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		jobs, err := h.service.List()
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, jobs)
+	case http.MethodPost:
+		h.submit(w, r)
+	default:
+		methodNotAllowed(w, r, http.MethodGet, http.MethodPost)
+	}
+}
+
+func (h *jobHandler) submit(w http.ResponseWriter, r *http.Request) {
+	name, file, pattern, err := readUpload(w, r, h.config.MaxUploadSize)
+	if tooLarge(err) {
+		h.writeTooLarge(w)
+		return
+	}
+	if err != nil {
+		writeError(w, errs.NewError(errs.ErrInvalidJob, err.Error()))
+		return
+	}
+	defer file.Close()
+
+	j, err := h.service.Submit(name, file, pattern)
+	if tooLarge(err) {
+		h.writeTooLarge(w)
+		return
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", "/jobs/"+j.ID)
+	writeJSON(w, http.StatusAccepted, j)
+}
+
+func (h *jobHandler) handleJob(w http.ResponseWriter, r *http.Request) {
+	id, resource := strings.TrimPrefix(r.URL.Path, "/jobs/"), ""
+	if i := strings.Index(id, "/"); i >= 0 {
+		id, resource = id[:i], id[i+1:]
+	}
+
+	switch {
+	case resource == "" && r.Method == http.MethodGet:
+		j, err := h.service.Get(id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, j)
+	case resource == "" && r.Method == http.MethodDelete:
+		j, err := h.service.Cancel(id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusAccepted, j)
+	case resource == "":
+		methodNotAllowed(w, r, http.MethodGet, http.MethodDelete)
+	case resource != "employees" && resource != "bad-data":
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: fmt.Sprintf("resource %q not found", resource)})
+	case r.Method != http.MethodGet:
+		methodNotAllowed(w, r, http.MethodGet)
+	default:
+		h.writeResult(w, id, resource)
+	}
+}
+
+// writeResult writes a result file of a finished job, or the empty value when the file was not written.
+func (h *jobHandler) writeResult(w http.ResponseWriter, id, resource string) {
+	j, err := h.service.Get(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if !j.Finished() {
+		writeJSON(w, http.StatusConflict, errorResponse{Error: fmt.Sprintf("the job is %s", j.State)})
+		return
+	}
+
+	path, empty := "", "[]"
+	if resource == "bad-data" {
+		empty = "{}"
+	}
+	if j.Summary != nil {
+		path = j.Summary.EmployeesFile
+		if resource == "bad-data" {
+			path = j.Summary.BadDataFile
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if path == "" {
+		io.WriteString(w, empty)
+		return
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer file.Close()
+
+	io.Copy(w, file)
+}
+
+func (h *jobHandler) writeTooLarge(w http.ResponseWriter) {
+	// the rest of the body was not read, so the connection can not be reused
+	w.Header().Set("Connection", "close")
+	writeJSON(w, http.StatusRequestEntityTooLarge, errorResponse{
+		Error: fmt.Sprintf("the upload is larger than %d bytes", h.config.MaxUploadSize),
+	})
+}
+
+// readUpload returns the file name, content and FilePattern of a multipart or raw upload, the request body
+// is limited to maxSize bytes.
+func readUpload(w http.ResponseWriter, r *http.Request, maxSize int64) (string, io.ReadCloser, *csv.FilePattern, error) {
+	r.Body = &limitedBody{body: r.Body, remaining: maxSize}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		if err := r.ParseMultipartForm(maxSize); err != nil {
+			return "", nil, nil, fmt.Errorf("invalid multipart form: %w", err)
+		}
+
+		pattern, err := decodePattern(r.FormValue("pattern"))
+		if err != nil {
+			return "", nil, nil, err
+		}
+
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("the file is required: %w", err)
+		}
+
+		return uploadName(header.Filename), file, pattern, nil
+	}
+
+	value := r.URL.Query().Get("pattern")
+	if value == "" {
+		value = r.Header.Get("X-File-Pattern")
+	}
+	pattern, err := decodePattern(value)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	return uploadName(r.URL.Query().Get("name")), r.Body, pattern, nil
+}
+
+// tooLarge reports if err was returned by the limitedBody of the upload.
+func tooLarge(err error) bool {
+	return stderrors.Is(err, errUploadTooLarge)
+}
+
+// limitedBody reads up to remaining bytes of the request body and then returns errUploadTooLarge, when the body
+// has more bytes.
+type limitedBody struct {
+	body      io.ReadCloser
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		// reads one more byte to tell a body of exactly the limit from a larger one
+		n, err := b.body.Read(make([]byte, 1))
+		if n > 0 {
+			return 0, errUploadTooLarge
+		}
+
+		return 0, err
+	}
+
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.body.Read(p)
+	b.remaining -= int64(n)
+
+	return n, err
+}
+
+func (b *limitedBody) Close() error {
+	return b.body.Close()
+}
+
+func uploadName(name string) string {
+	if name == "" {
+		return defaultFileName
+	}
+
+	return name
+}
+
+func decodePattern(value string) (*csv.FilePattern, error) {
+	if value == "" {
+		return nil, fmt.Errorf("the file pattern is required")
+	}
+
+	var pattern csv.FilePattern
+	if err := json.Unmarshal([]byte(value), &pattern); err != nil {
+		return nil, fmt.Errorf("invalid file pattern: %w", err)
+	}
+
+	return &pattern, nil
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func methodNotAllowed(w http.ResponseWriter, r *http.Request, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: fmt.Sprintf("method %s not allowed", r.Method)})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.WithFields(log.Fields{
+			"event":  "write_response_failed",
+			"reason": err,
+		}).Error()
+	}
+}
+
+// writeError writes the err with the status of its kind, the unknown errors are internal errors.
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case stderrors.Is(err, errs.ErrInvalidJob):
+		status = http.StatusBadRequest
+	case stderrors.Is(err, errs.ErrJobNotFound):
+		status = http.StatusNotFound
+	case stderrors.Is(err, errs.ErrJobFinished):
+		status = http.StatusConflict
+	}
+
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
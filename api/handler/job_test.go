@@ -0,0 +1,281 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/vsantosalmeida/csv-parser/api/handler"
+	"github.com/vsantosalmeida/csv-parser/usecase/csv"
+	"github.com/vsantosalmeida/csv-parser/usecase/job"
+)
+
+const (
+	givenRoster  = "Name,Email,Wage,Number\nJohn Doe,doe@test.com,$10.00,1\nMary Jane,marytest.com,$15,2\n"
+	givenPattern = `{"first_name":"Name","salary":"Wage","email":"Email","id":"Number"}`
+)
+
+type jobResponse struct {
+	ID       string       `json:"id"`
+	File     string       `json:"file"`
+	State    job.State    `json:"state"`
+	Progress csv.Progress `json:"progress"`
+	Summary  struct {
+		Employees int               `json:"employees"`
+		BadLines  int               `json:"bad_lines"`
+		Errors    map[string]string `json:"errors"`
+	} `json:"summary"`
+	Error string `json:"error"`
+}
+
+func init() {
+	log.SetOutput(ioutil.Discard)
+}
+
+func newServer(t *testing.T, config handler.Config) (*httptest.Server, *job.Service) {
+	dir, err := ioutil.TempDir("", "jobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	store, err := job.NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc, err := job.NewService(job.Config{Dir: dir}, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(svc.Close)
+
+	server := httptest.NewServer(handler.NewJobHandler(svc, config))
+	t.Cleanup(server.Close)
+
+	return server, svc
+}
+
+func wait(t *testing.T, svc *job.Service, id string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := svc.Wait(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func decode(t *testing.T, resp *http.Response, v interface{}) {
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestJobHandler_Multipart(t *testing.T) {
+	server, svc := newServer(t, handler.Config{})
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	w.WriteField("pattern", givenPattern)
+	part, _ := w.CreateFormFile("file", "roster.csv")
+	part.Write([]byte(givenRoster))
+	w.Close()
+
+	resp, err := http.Post(server.URL+"/jobs", w.FormDataContentType(), &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	var submitted jobResponse
+	decode(t, resp, &submitted)
+	assert.NotEmpty(t, submitted.ID)
+	assert.Equal(t, "roster.csv", submitted.File)
+	assert.Equal(t, "/jobs/"+submitted.ID, resp.Header.Get("Location"))
+	wait(t, svc, submitted.ID)
+
+	resp, err = http.Get(server.URL + "/jobs/" + submitted.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var got jobResponse
+	decode(t, resp, &got)
+	assert.Equal(t, submitted.ID, got.ID)
+	assert.Equal(t, job.StateSucceeded, got.State)
+	assert.Equal(t, csv.Progress{Rows: 2, Accepted: 1, Rejected: 1}, got.Progress)
+	assert.Equal(t, 1, got.Summary.Employees)
+	assert.Equal(t, 1, got.Summary.BadLines)
+
+	resp, err = http.Get(server.URL + "/jobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var jobs []jobResponse
+	decode(t, resp, &jobs)
+	if assert.Len(t, jobs, 1) {
+		assert.Equal(t, submitted.ID, jobs[0].ID)
+	}
+
+	resp, err = http.Get(server.URL + "/jobs/" + submitted.ID + "/employees")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var employees []map[string]interface{}
+	decode(t, resp, &employees)
+	if assert.Len(t, employees, 1) {
+		assert.Equal(t, "John Doe", employees[0]["name"])
+	}
+
+	resp, err = http.Get(server.URL + "/jobs/" + submitted.ID + "/bad-data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var badData map[string][]map[string]interface{}
+	decode(t, resp, &badData)
+	assert.Len(t, badData, 1)
+	for _, lines := range badData {
+		assert.Len(t, lines, 1)
+	}
+}
+
+func TestJobHandler_Raw(t *testing.T) {
+	server, svc := newServer(t, handler.Config{})
+
+	query := url.Values{"name": {"../roster.csv"}}
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/jobs?"+query.Encode(), strings.NewReader(givenRoster))
+	req.Header.Set("Content-Type", "text/csv")
+	req.Header.Set("X-File-Pattern", givenPattern)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	var submitted jobResponse
+	decode(t, resp, &submitted)
+	assert.Equal(t, "roster.csv", submitted.File)
+	wait(t, svc, submitted.ID)
+
+	got, err := svc.Get(submitted.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, job.StateSucceeded, got.State)
+	assert.Equal(t, 1, got.Summary.Employees)
+
+	// a finished job can not be cancelled.
+	req, _ = http.NewRequest(http.MethodDelete, server.URL+"/jobs/"+submitted.ID, nil)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+}
+
+func TestJobHandler_Errors(t *testing.T) {
+	server, _ := newServer(t, handler.Config{})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		header string
+		status int
+	}{
+		{name: "missing pattern", method: http.MethodPost, path: "/jobs", status: http.StatusBadRequest},
+		{name: "invalid pattern", method: http.MethodPost, path: "/jobs", header: "{", status: http.StatusBadRequest},
+		{name: "pattern without required columns", method: http.MethodPost, path: "/jobs", header: `{"email":"Email"}`, status: http.StatusBadRequest},
+		{name: "unknown job", method: http.MethodGet, path: "/jobs/unknown", status: http.StatusNotFound},
+		{name: "unknown job employees", method: http.MethodGet, path: "/jobs/unknown/employees", status: http.StatusNotFound},
+		{name: "unknown resource", method: http.MethodGet, path: "/jobs/unknown/other", status: http.StatusNotFound},
+		{name: "method not allowed", method: http.MethodDelete, path: "/jobs", status: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(givenRoster))
+			if tt.header != "" {
+				req.Header.Set("X-File-Pattern", tt.header)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.status, resp.StatusCode)
+
+			var got jobResponse
+			decode(t, resp, &got)
+			assert.NotEmpty(t, got.Error)
+		})
+	}
+}
+
+func TestJobHandler_UploadSizeLimit(t *testing.T) {
+	server, _ := newServer(t, handler.Config{MaxUploadSize: int64(len(givenRoster))})
+
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/jobs", strings.NewReader(givenRoster))
+	req.Header.Set("Content-Type", "text/csv")
+	req.Header.Set("X-File-Pattern", givenPattern)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+}
+
+func TestJobHandler_TooLarge(t *testing.T) {
+	server, svc := newServer(t, handler.Config{MaxUploadSize: 64})
+
+	var multipartBody bytes.Buffer
+	w := multipart.NewWriter(&multipartBody)
+	w.WriteField("pattern", givenPattern)
+	part, _ := w.CreateFormFile("file", "roster.csv")
+	part.Write([]byte(givenRoster))
+	w.Close()
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+	}{
+		{name: "multipart", contentType: w.FormDataContentType(), body: multipartBody.Bytes()},
+		{name: "raw", contentType: "text/csv", body: []byte(givenRoster)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPost, server.URL+"/jobs", bytes.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			req.Header.Set("X-File-Pattern", givenPattern)
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+
+			var got jobResponse
+			decode(t, resp, &got)
+			assert.Contains(t, got.Error, "64 bytes")
+		})
+	}
+
+	jobs, err := svc.List()
+	assert.NoError(t, err)
+	assert.Empty(t, jobs)
+}
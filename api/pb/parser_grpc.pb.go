@@ -0,0 +1,182 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.21.12
+// source: parser.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// ParserClient is the client API for Parser service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ParserClient interface {
+	// ParseFile parses a small file sent in a single message, the gRPC max message size applies.
+	ParseFile(ctx context.Context, in *ParseFileRequest, opts ...grpc.CallOption) (*ParseResponse, error)
+	// ParseRecords parses the records streamed by the client as a CSV file, the first message must have
+	// the pattern and the header.
+	ParseRecords(ctx context.Context, opts ...grpc.CallOption) (Parser_ParseRecordsClient, error)
+}
+
+type parserClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewParserClient(cc grpc.ClientConnInterface) ParserClient {
+	return &parserClient{cc}
+}
+
+func (c *parserClient) ParseFile(ctx context.Context, in *ParseFileRequest, opts ...grpc.CallOption) (*ParseResponse, error) {
+	out := new(ParseResponse)
+	err := c.cc.Invoke(ctx, "/csvparser.v1.Parser/ParseFile", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *parserClient) ParseRecords(ctx context.Context, opts ...grpc.CallOption) (Parser_ParseRecordsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Parser_ServiceDesc.Streams[0], "/csvparser.v1.Parser/ParseRecords", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &parserParseRecordsClient{stream}
+	return x, nil
+}
+
+type Parser_ParseRecordsClient interface {
+	Send(*ParseRecordsRequest) error
+	CloseAndRecv() (*ParseResponse, error)
+	grpc.ClientStream
+}
+
+type parserParseRecordsClient struct {
+	grpc.ClientStream
+}
+
+func (x *parserParseRecordsClient) Send(m *ParseRecordsRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *parserParseRecordsClient) CloseAndRecv() (*ParseResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ParseResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ParserServer is the server API for Parser service.
+// All implementations must embed UnimplementedParserServer
+// for forward compatibility
+type ParserServer interface {
+	// ParseFile parses a small file sent in a single message, the gRPC max message size applies.
+	ParseFile(context.Context, *ParseFileRequest) (*ParseResponse, error)
+	// ParseRecords parses the records streamed by the client as a CSV file, the first message must have
+	// the pattern and the header.
+	ParseRecords(Parser_ParseRecordsServer) error
+	mustEmbedUnimplementedParserServer()
+}
+
+// UnimplementedParserServer must be embedded to have forward compatible implementations.
+type UnimplementedParserServer struct {
+}
+
+func (UnimplementedParserServer) ParseFile(context.Context, *ParseFileRequest) (*ParseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ParseFile not implemented")
+}
+func (UnimplementedParserServer) ParseRecords(Parser_ParseRecordsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ParseRecords not implemented")
+}
+func (UnimplementedParserServer) mustEmbedUnimplementedParserServer() {}
+
+// UnsafeParserServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ParserServer will
+// result in compilation errors.
+type UnsafeParserServer interface {
+	mustEmbedUnimplementedParserServer()
+}
+
+func RegisterParserServer(s grpc.ServiceRegistrar, srv ParserServer) {
+	s.RegisterService(&Parser_ServiceDesc, srv)
+}
+
+func _Parser_ParseFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ParseFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ParserServer).ParseFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/csvparser.v1.Parser/ParseFile",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ParserServer).ParseFile(ctx, req.(*ParseFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Parser_ParseRecords_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ParserServer).ParseRecords(&parserParseRecordsServer{stream})
+}
+
+type Parser_ParseRecordsServer interface {
+	SendAndClose(*ParseResponse) error
+	Recv() (*ParseRecordsRequest, error)
+	grpc.ServerStream
+}
+
+type parserParseRecordsServer struct {
+	grpc.ServerStream
+}
+
+func (x *parserParseRecordsServer) SendAndClose(m *ParseResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *parserParseRecordsServer) Recv() (*ParseRecordsRequest, error) {
+	m := new(ParseRecordsRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Parser_ServiceDesc is the grpc.ServiceDesc for Parser service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Parser_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "csvparser.v1.Parser",
+	HandlerType: (*ParserServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ParseFile",
+			Handler:    _Parser_ParseFile_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ParseRecords",
+			Handler:       _Parser_ParseRecords_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "parser.proto",
+}
@@ -0,0 +1,315 @@
+package rpc
+
+import (
+	"context"
+	stdcsv "encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vsantosalmeida/csv-parser/api/pb"
+	"github.com/vsantosalmeida/csv-parser/entity"
+	"github.com/vsantosalmeida/csv-parser/usecase/csv"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// recordsFileName is the name of the CSV file written from the records of ParseRecords.
+const recordsFileName = "records.csv"
+
+// memberGlob matches the name of any member of an uploaded archive.
+const memberGlob = "*"
+
+type parserServer struct {
+	pb.UnimplementedParserServer
+	policy csv.Policy
+}
+
+// NewServer returns a grpc.Server with the pb.ParserServer registered, parsing each request with a new
+// csv.Parser following the policy.
+func NewServer(policy csv.Policy, opts ...grpc.ServerOption) (*grpc.Server, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	server := grpc.NewServer(opts...)
+	pb.RegisterParserServer(server, &parserServer{policy: policy})
+
+	return server, nil
+}
+
+func (s *parserServer) ParseFile(ctx context.Context, req *pb.ParseFileRequest) (*pb.ParseResponse, error) {
+	name := filepath.Base(filepath.Clean("/" + strings.ReplaceAll(req.GetName(), `\`, "/")))
+	if req.GetName() == "" || name == "/" {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid file name %q", req.GetName())
+	}
+
+	return s.parse(ctx, name, fromPattern(req.GetPattern()), func(w io.Writer) error {
+		_, err := w.Write(req.GetContent())
+		return err
+	})
+}
+
+func (s *parserServer) ParseRecords(stream pb.Parser_ParseRecordsServer) error {
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return status.Error(codes.InvalidArgument, "the pattern and the header are required")
+	}
+	if err != nil {
+		return err
+	}
+	if len(first.GetHeader()) == 0 {
+		return status.Error(codes.InvalidArgument, "the header is required in the first message")
+	}
+
+	// the records are always a CSV in UTF-8 with the default dialect.
+	pattern := fromPattern(first.GetPattern())
+	pattern.Format, pattern.Encoding, pattern.Dialect = csv.FormatCSV, csv.EncodingUTF8, nil
+
+	resp, err := s.parse(stream.Context(), recordsFileName, pattern, func(w io.Writer) error {
+		writer := stdcsv.NewWriter(w)
+		if err := writer.Write(first.GetHeader()); err != nil {
+			return err
+		}
+
+		for req := first; ; {
+			for _, record := range req.GetRecords() {
+				if err := writer.Write(record.GetValues()); err != nil {
+					return err
+				}
+			}
+
+			if req, err = stream.Recv(); err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		writer.Flush()
+		return writer.Error()
+	})
+	if err != nil {
+		return err
+	}
+
+	return stream.SendAndClose(resp)
+}
+
+// parse writes the file with write in a temp folder and parses it, reading the employees and bad data back
+// from the result files written in the same folder. The pattern is also used for every member of an archive.
+//
+// The parsing stops when the ctx of the call is done, returning the codes.Canceled or codes.DeadlineExceeded.
+func (s *parserServer) parse(ctx context.Context, name string, pattern *csv.FilePattern, write func(w io.Writer) error) (
+	*pb.ParseResponse, error) {
+	dir, err := ioutil.TempDir("", "csv-parser-rpc")
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, name)
+	patterns := map[string]*csv.FilePattern{path: pattern, memberGlob: pattern}
+	parser, err := csv.NewParser(patterns, csv.WithPolicy(s.policy), csv.WithOutputDir(dir), csv.WithContext(ctx))
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := writeFile(path, write); err != nil {
+		return nil, err
+	}
+
+	log.WithFields(log.Fields{
+		"event": "rpc_parse_started",
+		"file":  name,
+	}).Info()
+	parser.ParseFiles([]string{path})
+	if err := ctx.Err(); err != nil {
+		log.WithFields(log.Fields{
+			"event":  "rpc_parse_cancelled",
+			"file":   name,
+			"reason": err,
+		}).Warn()
+		return nil, status.FromContextError(err).Err()
+	}
+	summary := parser.Summary()
+
+	resp := &pb.ParseResponse{
+		Summary: &pb.Summary{
+			Employees: int32(summary.Employees),
+			BadLines:  int32(summary.BadLines),
+		},
+	}
+
+	if len(summary.Errors) != 0 {
+		resp.Summary.Errors = make(map[string]string, len(summary.Errors))
+		for k, v := range summary.Errors {
+			// the errors of the file are reported by its name, not by the temp path.
+			resp.Summary.Errors[strings.ReplaceAll(k, path, name)] = strings.ReplaceAll(v, path, name)
+		}
+	}
+
+	if summary.EmployeesFile != "" {
+		var employees []*entity.Employee
+		if err := readJSON(summary.EmployeesFile, &employees); err != nil {
+			return nil, err
+		}
+		for _, e := range employees {
+			resp.Employees = append(resp.Employees, toEmployee(e))
+		}
+	}
+
+	if summary.BadDataFile != "" {
+		var badData map[string][]*csv.BadData
+		if err := readJSON(summary.BadDataFile, &badData); err != nil {
+			return nil, err
+		}
+		// the members of an archive are stored by "path!member" and reported by "name!member".
+		keys := make([]string, 0, len(badData))
+		for key := range badData {
+			if key == path || strings.HasPrefix(key, path+csv.MemberSeparator) {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			file := name + strings.TrimPrefix(key, path)
+			for _, line := range badData[key] {
+				resp.BadData = append(resp.BadData, &pb.BadData{Line: line.Line.String(), Reasons: line.Reasons, File: file})
+			}
+		}
+	}
+
+	return resp, nil
+}
+
+func writeFile(path string, write func(w io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	if err := write(file); err != nil {
+		file.Close()
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		return status.Error(codes.InvalidArgument, fmt.Sprintf("could not write the file: %s", err))
+	}
+
+	if err := file.Close(); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	return nil
+}
+
+func readJSON(path string, v interface{}) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	if err := json.Unmarshal(b, v); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	return nil
+}
+
+// fromPattern converts the pb.FilePattern, a nil pattern is an empty one rejected by csv.NewParser.
+func fromPattern(p *pb.FilePattern) *csv.FilePattern {
+	pattern := &csv.FilePattern{
+		FirstNameColumn: p.GetFirstName(),
+		LastNameColumn:  p.GetLastName(),
+		SalaryColumn:    p.GetSalary(),
+		EmailColumn:     p.GetEmail(),
+		IDColumn:        p.GetId(),
+		PhoneColumn:     p.GetPhone(),
+		Format:          csv.Format(p.GetFormat()),
+		Encoding:        p.GetEncoding(),
+		Element:         p.GetElement(),
+	}
+
+	if d := p.GetDialect(); d != nil {
+		pattern.Dialect = &csv.Dialect{
+			Delimiter:        d.GetDelimiter(),
+			Comment:          d.GetComment(),
+			LazyQuotes:       d.GetLazyQuotes(),
+			TrimLeadingSpace: d.GetTrimLeadingSpace(),
+			FieldsPerRecord:  int(d.GetFieldsPerRecord()),
+		}
+	}
+
+	if sh := p.GetSheet(); sh != nil {
+		pattern.Sheet = &csv.Sheet{
+			Name:      sh.GetName(),
+			HeaderRow: int(sh.GetHeaderRow()),
+		}
+	}
+
+	if fw := p.GetFixedWidth(); fw != nil {
+		pattern.FixedWidth = &csv.FixedWidth{
+			Trim:            fw.GetTrim(),
+			SkipLines:       int(fw.GetSkipLines()),
+			AllowShortLines: fw.GetAllowShortLines(),
+		}
+		for _, c := range fw.GetColumns() {
+			pattern.FixedWidth.Columns = append(pattern.FixedWidth.Columns, &csv.FixedWidthColumn{
+				Name:   c.GetName(),
+				Start:  int(c.GetStart()),
+				Length: int(c.GetLength()),
+				Trim:   c.GetTrim(),
+			})
+		}
+	}
+
+	for _, a := range p.GetAttributes() {
+		pattern.Attributes = append(pattern.Attributes, &csv.AttributePattern{
+			Name:     a.GetName(),
+			Column:   a.GetColumn(),
+			Type:     csv.AttributeType(a.GetType()),
+			Required: a.GetRequired(),
+			Pattern:  a.GetPattern(),
+			Values:   a.GetValues(),
+			Min:      a.Min,
+			Max:      a.Max,
+			Layout:   a.GetLayout(),
+		})
+	}
+
+	return pattern
+}
+
+func toEmployee(e *entity.Employee) *pb.Employee {
+	employee := &pb.Employee{
+		Id:     e.ID,
+		Email:  e.Email,
+		Name:   e.Name,
+		Salary: e.Salary,
+		Phone:  e.Phone,
+	}
+
+	if len(e.Attributes) != 0 {
+		employee.Attributes = make(map[string]*structpb.Value, len(e.Attributes))
+		for name, value := range e.Attributes {
+			v, err := structpb.NewValue(value)
+			if err != nil {
+				v = structpb.NewStringValue(entity.FormatAttribute(value))
+			}
+			employee.Attributes[name] = v
+		}
+	}
+
+	return employee
+}
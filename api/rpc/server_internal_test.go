@@ -0,0 +1,41 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vsantosalmeida/csv-parser/api/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestParserServer_ParseFile_ContextDone(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want codes.Code
+	}{
+		{name: "cancelled", ctx: cancelled, want: codes.Canceled},
+		{name: "deadline exceeded", ctx: expired, want: codes.DeadlineExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &parserServer{}
+			got, err := server.ParseFile(tt.ctx, &pb.ParseFileRequest{
+				Name:    "roster.csv",
+				Content: []byte("Name,Email,Wage,Number\nJohn Doe,doe@test.com,$10.00,1\n"),
+				Pattern: &pb.FilePattern{FirstName: "Name", Salary: "Wage", Email: "Email", Id: "Number"},
+			})
+			assert.Nil(t, got)
+			assert.Equal(t, tt.want, status.Code(err))
+		})
+	}
+}
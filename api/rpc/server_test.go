@@ -0,0 +1,260 @@
+package rpc_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/vsantosalmeida/csv-parser/api/pb"
+	"github.com/vsantosalmeida/csv-parser/api/rpc"
+	"github.com/vsantosalmeida/csv-parser/usecase/csv"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const givenRoster = "Name,Email,Wage,Number\nJohn Doe,doe@test.com,$10.00,1\nMary Jane,marytest.com,$15,2\n"
+
+var givenPattern = &pb.FilePattern{
+	FirstName: "Name",
+	Salary:    "Wage",
+	Email:     "Email",
+	Id:        "Number",
+}
+
+func init() {
+	log.SetOutput(ioutil.Discard)
+}
+
+// newClient starts an in-process server on a bufconn listener.
+func newClient(t *testing.T) pb.ParserClient {
+	server, err := rpc.NewServer(csv.Policy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listener := bufconn.Listen(1 << 20)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewParserClient(conn)
+}
+
+func TestParserServer_ParseFile(t *testing.T) {
+	client := newClient(t)
+
+	got, err := client.ParseFile(context.Background(), &pb.ParseFileRequest{
+		Name:    "roster.csv",
+		Content: []byte(givenRoster),
+		Pattern: givenPattern,
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, got.GetEmployees(), 1) {
+		want := &pb.Employee{Id: "1", Email: "doe@test.com", Name: "John Doe", Salary: 10}
+		assert.Equal(t, want.String(), got.GetEmployees()[0].String())
+	}
+	if assert.Len(t, got.GetBadData(), 1) {
+		assert.Equal(t, "3", got.GetBadData()[0].GetLine())
+		assert.Equal(t, "roster.csv", got.GetBadData()[0].GetFile())
+		assert.NotEmpty(t, got.GetBadData()[0].GetReasons())
+	}
+	assert.Equal(t, int32(1), got.GetSummary().GetEmployees())
+	assert.Equal(t, int32(1), got.GetSummary().GetBadLines())
+	assert.Empty(t, got.GetSummary().GetErrors())
+}
+
+func TestParserServer_ParseFile_Archive(t *testing.T) {
+	client := newClient(t)
+
+	var content bytes.Buffer
+	w := zip.NewWriter(&content)
+	members := map[string]string{
+		"east.csv":      givenRoster,
+		"west/west.csv": "Name,Email,Wage,Number\nJane Roe,roe@test.com,$12,3\nJack Roe,jack@test.com,abc,4\n",
+	}
+	for _, name := range []string{"east.csv", "west/west.csv"} {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(members[name])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := client.ParseFile(context.Background(), &pb.ParseFileRequest{
+		Name:    "roster.zip",
+		Content: content.Bytes(),
+		Pattern: givenPattern,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, got.GetEmployees(), 2)
+	assert.Empty(t, got.GetSummary().GetErrors())
+	if assert.Len(t, got.GetBadData(), 2) {
+		assert.Equal(t, "roster.zip!east.csv", got.GetBadData()[0].GetFile())
+		assert.Equal(t, "3", got.GetBadData()[0].GetLine())
+		assert.Equal(t, "roster.zip!west/west.csv", got.GetBadData()[1].GetFile())
+		assert.Equal(t, "3", got.GetBadData()[1].GetLine())
+	}
+}
+
+func TestParserServer_ParseFile_FixedWidth(t *testing.T) {
+	client := newClient(t)
+
+	got, err := client.ParseFile(context.Background(), &pb.ParseFileRequest{
+		Name:    "roster.txt",
+		Content: []byte("HEADER\n1    John Doe  doe@test.com  10.00\n"),
+		Pattern: &pb.FilePattern{
+			FirstName: "name",
+			Salary:    "salary",
+			Email:     "email",
+			Id:        "id",
+			Format:    "fixed",
+			FixedWidth: &pb.FixedWidth{
+				SkipLines: 1,
+				Columns: []*pb.FixedWidthColumn{
+					{Name: "id", Start: 1, Length: 5},
+					{Name: "name", Start: 6, Length: 10},
+					{Name: "email", Start: 16, Length: 14},
+					{Name: "salary", Start: 30, Length: 5},
+				},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, got.GetEmployees(), 1) {
+		want := &pb.Employee{Id: "1", Email: "doe@test.com", Name: "John Doe", Salary: 10}
+		assert.Equal(t, want.String(), got.GetEmployees()[0].String())
+	}
+	assert.Empty(t, got.GetBadData())
+	assert.Empty(t, got.GetSummary().GetErrors())
+}
+
+func TestParserServer_ParseFile_Attributes(t *testing.T) {
+	client := newClient(t)
+	max := float64(5)
+
+	got, err := client.ParseFile(context.Background(), &pb.ParseFileRequest{
+		Name:    "roster.csv",
+		Content: []byte("Name,Email,Wage,Number,Dept,Level\nJohn Doe,doe@test.com,$10.00,1,Sales,3\nMary Jane,mary@test.com,$15,2,IT,9\n"),
+		Pattern: &pb.FilePattern{
+			FirstName: "Name",
+			Salary:    "Wage",
+			Email:     "Email",
+			Id:        "Number",
+			Attributes: []*pb.Attribute{
+				{Name: "department", Column: "Dept"},
+				{Name: "level", Column: "Level", Type: "number", Max: &max},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, got.GetEmployees(), 1) {
+		attributes := got.GetEmployees()[0].GetAttributes()
+		assert.Equal(t, "Sales", attributes["department"].GetStringValue())
+		assert.Equal(t, float64(3), attributes["level"].GetNumberValue())
+	}
+	assert.Len(t, got.GetBadData(), 1)
+}
+
+func TestParserServer_ParseFile_FileError(t *testing.T) {
+	client := newClient(t)
+
+	// the content is not a workbook, so the file can not be read.
+	got, err := client.ParseFile(context.Background(), &pb.ParseFileRequest{
+		Name:    "roster.xlsx",
+		Content: []byte(givenRoster),
+		Pattern: givenPattern,
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, got.GetEmployees())
+	if assert.Len(t, got.GetSummary().GetErrors(), 1) {
+		assert.Contains(t, got.GetSummary().GetErrors(), "roster.xlsx")
+	}
+}
+
+func TestParserServer_ParseFile_InvalidArgument(t *testing.T) {
+	client := newClient(t)
+
+	tests := []struct {
+		name string
+		req  *pb.ParseFileRequest
+	}{
+		{name: "missing name", req: &pb.ParseFileRequest{Content: []byte(givenRoster), Pattern: givenPattern}},
+		{name: "missing pattern", req: &pb.ParseFileRequest{Name: "roster.csv", Content: []byte(givenRoster)}},
+		{name: "invalid format", req: &pb.ParseFileRequest{Name: "roster.csv", Pattern: &pb.FilePattern{
+			FirstName: "Name", Salary: "Wage", Email: "Email", Id: "Number", Format: "doc",
+		}}},
+		{name: "invalid sheet", req: &pb.ParseFileRequest{Name: "roster.xlsx", Pattern: &pb.FilePattern{
+			FirstName: "Name", Salary: "Wage", Email: "Email", Id: "Number", Sheet: &pb.Sheet{HeaderRow: -1},
+		}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.ParseFile(context.Background(), tt.req)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
+
+func TestParserServer_ParseRecords(t *testing.T) {
+	client := newClient(t)
+
+	stream, err := client.ParseRecords(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, stream.Send(&pb.ParseRecordsRequest{
+		Pattern: givenPattern,
+		Header:  []string{"Name", "Email", "Wage", "Number"},
+		Records: []*pb.Record{{Values: []string{"John Doe", "doe@test.com", "$10.00", "1"}}},
+	}))
+	assert.NoError(t, stream.Send(&pb.ParseRecordsRequest{
+		Records: []*pb.Record{
+			{Values: []string{"Mary Jane", "marytest.com", "$15", "2"}},
+			{Values: []string{"Max, Topperson", "max@test.com", "$11", "3"}},
+		},
+	}))
+
+	got, err := stream.CloseAndRecv()
+	assert.NoError(t, err)
+	if assert.Len(t, got.GetEmployees(), 2) {
+		assert.Equal(t, "Max, Topperson", got.GetEmployees()[1].GetName())
+	}
+	if assert.Len(t, got.GetBadData(), 1) {
+		assert.Equal(t, "3", got.GetBadData()[0].GetLine())
+	}
+}
+
+func TestParserServer_ParseRecords_MissingHeader(t *testing.T) {
+	client := newClient(t)
+
+	stream, err := client.ParseRecords(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, stream.Send(&pb.ParseRecordsRequest{Pattern: givenPattern}))
+
+	_, err = stream.CloseAndRecv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+
+	log "github.com/sirupsen/logrus"
+	errs "github.com/vsantosalmeida/csv-parser/pkg/errors"
+	"github.com/vsantosalmeida/csv-parser/usecase/csv"
+)
+
+func runConvert(args []string) int {
+	var in, out string
+	fs := newFlagSet("convert", "-i=employee-20210101120000.json -o=employees.csv",
+		"Write a result file again in another format, the formats are taken from the files' extensions.\n"+
+			"The input must be json or ndjson, and the output json, ndjson, csv or xml.")
+	fs.StringVar(&in, "i", "", "Result file to convert")
+	fs.StringVar(&out, "o", "", "File to write the converted result")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if in == "" || out == "" {
+		log.WithFields(log.Fields{
+			"event": "empty_convert_args",
+		}).Error("the `-i` and `-o` args are required to convert a file")
+		fs.Usage()
+		return exitUsage
+	}
+
+	if err := csv.ConvertResultFile(in, out); err != nil {
+		log.WithFields(log.Fields{
+			"event":  "convert_file_failed",
+			"input":  in,
+			"output": out,
+			"reason": err,
+		}).Error("could not convert the result file")
+
+		switch {
+		case errors.Is(err, errs.ErrUnsupportedFormat):
+			return exitUsage
+		case errors.Is(err, errs.ErrWriteFile):
+			return exitWriteFailure
+		default:
+			return exitFileFailure
+		}
+	}
+
+	return exitOK
+}
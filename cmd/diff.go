@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vsantosalmeida/csv-parser/entity"
+	errs "github.com/vsantosalmeida/csv-parser/pkg/errors"
+	"github.com/vsantosalmeida/csv-parser/usecase/csv"
+)
+
+func runDiff(args []string) int {
+	var previous, current, out string
+	fs := newFlagSet("diff", "-old=employee-20210101120000.json -new=employee-20210108120000.json [-o=diff.json]",
+		"Compare two employees result files (json or ndjson) by ID, printing the added, removed and changed employees.")
+	fs.StringVar(&previous, "old", "", "Previous employees result file")
+	fs.StringVar(&current, "new", "", "Current employees result file")
+	fs.StringVar(&out, "o", "", "File to write the diff, printed to stdout when empty")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if previous == "" || current == "" {
+		log.WithFields(log.Fields{
+			"event": "empty_diff_args",
+		}).Error("the `-old` and `-new` args are required to compare the files")
+		fs.Usage()
+		return exitUsage
+	}
+
+	oldEmployees, code := loadEmployees(previous)
+	if code != exitOK {
+		return code
+	}
+	newEmployees, code := loadEmployees(current)
+	if code != exitOK {
+		return code
+	}
+
+	var buf bytes.Buffer
+	if err := encodeJSON(&buf, csv.DiffEmployees(oldEmployees, newEmployees)); err != nil {
+		return exitWriteFailure
+	}
+
+	var err error
+	if out == "" {
+		_, err = os.Stdout.Write(buf.Bytes())
+	} else {
+		err = ioutil.WriteFile(out, buf.Bytes(), 0644)
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"event":  "write_diff_failed",
+			"output": out,
+			"reason": err,
+		}).Error("could not write the diff")
+		return exitWriteFailure
+	}
+
+	return exitOK
+}
+
+// loadEmployees reads an employees result file, returning the exit code of the failure.
+func loadEmployees(file string) ([]*entity.Employee, int) {
+	employees, err := csv.LoadEmployeesFile(file)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"event":  "load_employees_file_failed",
+			"file":   file,
+			"reason": err,
+		}).Error("could not load the employees result file")
+
+		if errors.Is(err, errs.ErrUnsupportedFormat) {
+			return nil, exitUsage
+		}
+		return nil, exitFileFailure
+	}
+
+	return employees, exitOK
+}
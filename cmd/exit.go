@@ -0,0 +1,38 @@
+package main
+
+import (
+	"errors"
+
+	errs "github.com/vsantosalmeida/csv-parser/pkg/errors"
+	"github.com/vsantosalmeida/csv-parser/usecase/csv"
+)
+
+// Exit codes of the process, from the most to the least severe failure the highest one is used.
+const (
+	// exitOK all the files were processed without bad data.
+	exitOK = 0
+	// exitUsage the args or the file patterns are invalid, no file was processed.
+	exitUsage = 2
+	// exitBadData all the files were processed but some lines were written to the bad data file.
+	exitBadData = 3
+	// exitFileFailure at least one file could not be processed or was rejected by the run policy.
+	exitFileFailure = 4
+	// exitWriteFailure a result file could not be written, or the results could not be written to a sink.
+	exitWriteFailure = 5
+)
+
+func exitCode(summary *csv.Summary, parseErrs map[string]error) int {
+	code := exitOK
+	if summary != nil && summary.BadLines > 0 {
+		code = exitBadData
+	}
+
+	for _, err := range parseErrs {
+		if errors.Is(err, errs.ErrWriteFile) || errors.Is(err, errs.ErrWriteSink) {
+			return exitWriteFailure
+		}
+		code = exitFileFailure
+	}
+
+	return code
+}
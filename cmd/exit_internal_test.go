@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	errs "github.com/vsantosalmeida/csv-parser/pkg/errors"
+	"github.com/vsantosalmeida/csv-parser/usecase/csv"
+)
+
+func TestExitCode(t *testing.T) {
+	var (
+		fileErr  = &errs.FileError{Path: "roster1.csv", Op: errs.OpOpen, Err: errs.NewError(errs.ErrOpeningFile, "no such file")}
+		writeErr = &errs.FileError{Path: "employee.json", Op: errs.OpWrite, Err: errs.NewError(errs.ErrWriteFile, "disk full")}
+		sinkErr  = &errs.FileError{Path: "roster1.csv", Op: errs.OpWrite, Err: errs.NewError(errs.ErrWriteSink, "timeout")}
+	)
+
+	tt := []struct {
+		name           string
+		givenSummary   *csv.Summary
+		givenParseErrs map[string]error
+		want           int
+	}{
+		{
+			name:         "Clean run",
+			givenSummary: &csv.Summary{Files: 1, Employees: 3},
+			want:         exitOK,
+		},
+		{
+			name:         "Without summary",
+			givenSummary: nil,
+			want:         exitOK,
+		},
+		{
+			name:         "Bad data only",
+			givenSummary: &csv.Summary{Files: 1, Employees: 3, BadLines: 2},
+			want:         exitBadData,
+		},
+		{
+			name:           "File error",
+			givenSummary:   &csv.Summary{Files: 2, Employees: 3},
+			givenParseErrs: map[string]error{"roster1.csv": fileErr},
+			want:           exitFileFailure,
+		},
+		{
+			name:           "Policy error",
+			givenSummary:   &csv.Summary{Files: 1},
+			givenParseErrs: map[string]error{"roster1.csv": errors.New("rejected")},
+			want:           exitFileFailure,
+		},
+		{
+			name:           "Write error",
+			givenSummary:   &csv.Summary{Files: 1, Employees: 3},
+			givenParseErrs: map[string]error{"writeEmployeesFile": writeErr},
+			want:           exitWriteFailure,
+		},
+		{
+			name:           "Sink error",
+			givenSummary:   &csv.Summary{Files: 1, Employees: 3},
+			givenParseErrs: map[string]error{"writeSink:roster1.csv": sinkErr},
+			want:           exitWriteFailure,
+		},
+		{
+			name:           "Bad data and file error",
+			givenSummary:   &csv.Summary{Files: 2, Employees: 3, BadLines: 2},
+			givenParseErrs: map[string]error{"roster1.csv": fileErr},
+			want:           exitFileFailure,
+		},
+		{
+			name:         "Bad data, file and write errors",
+			givenSummary: &csv.Summary{Files: 2, Employees: 3, BadLines: 2},
+			givenParseErrs: map[string]error{
+				"roster1.csv":        fileErr,
+				"roster2.csv":        fileErr,
+				"writeEmployeesFile": writeErr,
+			},
+			want: exitWriteFailure,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, exitCode(tc.givenSummary, tc.givenParseErrs))
+		})
+	}
+}
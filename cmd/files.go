@@ -0,0 +1,200 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// fileFlags select the files processed by a command, the -f values can be file names, globs or directories.
+type fileFlags struct {
+	files     string
+	recursive bool
+	include   string
+	exclude   string
+}
+
+func (f *fileFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.files, "f", "", `Files names, globs like "drop/*.csv" or directories separated by ","`)
+	fs.BoolVar(&f.recursive, "r", false, "Read the files of the subdirectories of the -f directories")
+	fs.StringVar(&f.include, "include", "", `Globs separated by "," of the files names to read from the -f globs and directories, like "*.csv,*.xlsx"`)
+	fs.StringVar(&f.exclude, "exclude", "", `Globs separated by "," of the files names to ignore from the -f globs and directories`)
+}
+
+// empty reports if no file was given.
+func (f *fileFlags) empty() bool {
+	return strings.Trim(f.files, " ") == ""
+}
+
+// expand returns the files of the -f flag in their order, the globs and directories are replaced by their files
+// sorted by name and filtered by the include and exclude flags.
+// The hidden files and directories, like ".git", are ignored in the directories and by the globs unless the glob
+// name starts with ".", and the symbolic links to directories are read as directories.
+// The file names are returned as they are, so a missing file is reported by the parser.
+func (f *fileFlags) expand() ([]string, error) {
+	include, err := splitGlobs(f.include)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -include: %w", err)
+	}
+	exclude, err := splitGlobs(f.exclude)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -exclude: %w", err)
+	}
+
+	selected := func(path string) bool {
+		name := filepath.Base(path)
+		if len(include) != 0 && !matchAny(include, name) {
+			return false
+		}
+		return !matchAny(exclude, name)
+	}
+
+	var (
+		files   []string
+		seen    = make(map[string]bool)
+		visited = make(map[string]bool)
+	)
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+	addSelected := func(path string) {
+		if selected(path) {
+			add(path)
+		}
+	}
+
+	for _, arg := range strings.Split(f.files, ",") {
+		arg = strings.TrimSpace(arg)
+		if arg == "" {
+			continue
+		}
+
+		paths := []string{arg}
+		if strings.ContainsAny(arg, "*?[") {
+			if paths, err = glob(arg); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, path := range paths {
+			info, err := os.Stat(path)
+			if err != nil || !info.IsDir() {
+				if path == arg {
+					add(path)
+				} else {
+					addSelected(path)
+				}
+				continue
+			}
+
+			if err := f.walk(path, visited, addSelected); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no file found in %q", f.files)
+	}
+
+	return files, nil
+}
+
+// glob returns the paths matching the pattern, the hidden ones only when the pattern name starts with ".".
+func glob(pattern string) ([]string, error) {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob %q: %w", pattern, err)
+	}
+
+	var paths []string
+	for _, path := range matches {
+		if !hidden(filepath.Base(path)) || hidden(filepath.Base(pattern)) {
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no file matches %q", pattern)
+	}
+
+	return paths, nil
+}
+
+// walk calls add with the files of the dir sorted by name, and with the files of its subdirectories when recursive.
+// The visited directories are skipped, so a symbolic link to a parent directory is read once.
+func (f *fileFlags) walk(dir string, visited map[string]bool, add func(path string)) error {
+	real, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+	if visited[real] {
+		return nil
+	}
+	visited[real] = true
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if hidden(entry.Name()) {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		isDir := entry.IsDir()
+		if entry.Type()&fs.ModeSymlink != 0 {
+			if info, err := os.Stat(path); err == nil {
+				isDir = info.IsDir()
+			}
+		}
+
+		if !isDir {
+			add(path)
+			continue
+		}
+		if f.recursive {
+			if err := f.walk(path, visited, add); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func hidden(name string) bool {
+	return strings.HasPrefix(name, ".") && name != "." && name != ".."
+}
+
+func splitGlobs(value string) ([]string, error) {
+	var globs []string
+	for _, glob := range strings.Split(value, ",") {
+		if glob = strings.TrimSpace(glob); glob == "" {
+			continue
+		}
+		if _, err := filepath.Match(glob, ""); err != nil {
+			return nil, fmt.Errorf("%q: %w", glob, err)
+		}
+		globs = append(globs, glob)
+	}
+
+	return globs, nil
+}
+
+func matchAny(globs []string, name string) bool {
+	for _, glob := range globs {
+		if ok, _ := filepath.Match(glob, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
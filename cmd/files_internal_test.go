@@ -0,0 +1,144 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newFilesTree creates the files and symbolic links below a temp dir and returns its path.
+func newFilesTree(t *testing.T) string {
+	root, err := ioutil.TempDir("", "files")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(root) })
+
+	for _, file := range []string{
+		"drop/a.csv",
+		"drop/b.csv",
+		"drop/c.bak.csv",
+		"drop/notes.txt",
+		"drop/.hidden.csv",
+		"drop/.git/d.csv",
+		"drop/sub/e.csv",
+		"other/f.csv",
+	} {
+		path := filepath.Join(root, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	links := map[string]string{
+		"drop/linked":   "../other",
+		"drop/sub/loop": "..",
+		"linked":        "other",
+	}
+	for link, target := range links {
+		if err := os.Symlink(target, filepath.Join(root, link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return root
+}
+
+func TestFileFlags_Expand(t *testing.T) {
+	root := newFilesTree(t)
+
+	tt := []struct {
+		name      string
+		givenArgs fileFlags
+		want      []string
+		wantErr   string
+	}{
+		{
+			name:      "Directory without the hidden files and the subdirectories",
+			givenArgs: fileFlags{files: "drop"},
+			want:      []string{"drop/a.csv", "drop/b.csv", "drop/c.bak.csv", "drop/notes.txt"},
+		},
+		{
+			name:      "Recursive directory following the symlinked directories once",
+			givenArgs: fileFlags{files: "drop", recursive: true, include: "*.csv"},
+			want:      []string{"drop/a.csv", "drop/b.csv", "drop/c.bak.csv", "drop/linked/f.csv", "drop/sub/e.csv"},
+		},
+		{
+			name:      "Symlinked directory",
+			givenArgs: fileFlags{files: "linked"},
+			want:      []string{"linked/f.csv"},
+		},
+		{
+			name:      "Overlapping globs",
+			givenArgs: fileFlags{files: "drop/*.csv, drop/a*, drop"},
+			want:      []string{"drop/a.csv", "drop/b.csv", "drop/c.bak.csv", "drop/notes.txt"},
+		},
+		{
+			name:      "Exclude wins over include",
+			givenArgs: fileFlags{files: "drop", include: "*.csv", exclude: "*.bak.csv,b.csv"},
+			want:      []string{"drop/a.csv"},
+		},
+		{
+			name:      "Exclude all the included files",
+			givenArgs: fileFlags{files: "drop", include: "*.csv", exclude: "*.csv"},
+			wantErr:   "no file found",
+		},
+		{
+			name:      "Hidden files by a hidden glob and by name",
+			givenArgs: fileFlags{files: "drop/.*.csv,drop/.git/d.csv"},
+			want:      []string{"drop/.hidden.csv", "drop/.git/d.csv"},
+		},
+		{
+			name:      "Files by name are not filtered",
+			givenArgs: fileFlags{files: "drop/notes.txt,drop/missing.csv", include: "*.csv"},
+			want:      []string{"drop/notes.txt", "drop/missing.csv"},
+		},
+		{
+			name:      "Glob matching nothing",
+			givenArgs: fileFlags{files: "drop/*.xlsx"},
+			wantErr:   "no file matches",
+		},
+		{
+			name:      "Glob matching only hidden files",
+			givenArgs: fileFlags{files: "drop/*hidden.csv"},
+			wantErr:   "no file matches",
+		},
+		{
+			name:      "Invalid include",
+			givenArgs: fileFlags{files: "drop", include: "[a"},
+			wantErr:   "invalid -include",
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			flags := tc.givenArgs
+			var files []string
+			for _, file := range strings.Split(flags.files, ",") {
+				files = append(files, filepath.Join(root, strings.TrimSpace(file)))
+			}
+			flags.files = strings.Join(files, ",")
+
+			got, err := flags.expand()
+			if tc.wantErr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tc.wantErr)
+				}
+				return
+			}
+
+			assert.NoError(t, err)
+			var want []string
+			for _, file := range tc.want {
+				want = append(want, filepath.Join(root, file))
+			}
+			assert.Equal(t, want, got)
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vsantosalmeida/csv-parser/usecase/csv"
+)
+
+// parserFlags are the flags shared by the commands that process CSV files with a csv.Parser.
+type parserFlags struct {
+	fileFlags
+	patterns   string
+	summary    string
+	format     string
+	merge      string
+	provenance string
+	policy     csv.Policy
+}
+
+func (p *parserFlags) register(fs *flag.FlagSet) {
+	p.fileFlags.register(fs)
+	fs.StringVar(&p.patterns, "p", "", "JSON file with the FilePattern of each file, when empty the columns' names are asked for each file")
+	fs.StringVar(&p.format, "format", "", "Format of the input files without a format in the file patterns (csv, xlsx, json, ndjson, xml or fixed), from each file extension when empty")
+	fs.StringVar(&p.summary, "summary", summaryText, `Format of the run summary printed to stdout, "text" or "json"`)
+	fs.StringVar(&p.merge, "merge", "", "Merge the employees with the same ID in several files into one, taking the fields by file_order, non_empty or most_recent, and write a lineage file")
+	fs.StringVar(&p.provenance, "provenance", "", `Record the file, line and columns of each employee, "embedded" in its "_meta" object or in a "sidecar" provenance file`)
+	registerPolicy(fs, &p.policy)
+}
+
+func registerPolicy(fs *flag.FlagSet, policy *csv.Policy) {
+	fs.BoolVar(&policy.FailFast, "fail-fast", false, "Stop the run on the first invalid line")
+	fs.IntVar(&policy.MaxBadLines, "max-bad-lines", 0, "Reject a file with more bad lines than the given value, 0 means no limit")
+	fs.Float64Var(&policy.MaxBadRatio, "max-bad-ratio", 0, "Reject a file when the ratio of bad lines is greater than the given value (0 to 1), 0 means no limit")
+	fs.BoolVar(&policy.AllOrNothing, "all-or-nothing", false, "Do not write the employees file if any file finished with errors")
+}
+
+// parser validates the flags and creates a csv.Parser for the files with the extra opts, returning exitUsage
+// when it fails.
+func (p *parserFlags) parser(fs *flag.FlagSet, opts ...csv.Option) (csv.Parser, []string, int) {
+	if p.empty() {
+		log.WithFields(log.Fields{
+			"event": "empty_files_arg",
+		}).Error("the `-f` arg is required to process a file and must not be empty")
+		fs.Usage()
+		return nil, nil, exitUsage
+	}
+
+	if p.summary != summaryText && p.summary != summaryJSON {
+		log.WithFields(log.Fields{
+			"event":   "invalid_summary_arg",
+			"summary": p.summary,
+		}).Error(`the "-summary" arg must be "text" or "json"`)
+		fs.Usage()
+		return nil, nil, exitUsage
+	}
+
+	files, err := p.expand()
+	if err != nil {
+		log.WithFields(log.Fields{
+			"event":  "invalid_files_arg",
+			"files":  p.files,
+			"reason": err,
+		}).Error("could not find the files of the `-f` arg")
+		return nil, nil, exitUsage
+	}
+
+	var filePatterns map[string]*csv.FilePattern
+	if p.patterns != "" {
+		filePatterns, err = csv.LoadFilePatternMap(p.patterns)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"event":  "load_file_patterns_error",
+				"file":   p.patterns,
+				"reason": err,
+			}).Error("could not load the file patterns")
+			return nil, nil, exitUsage
+		}
+	} else {
+		filePatterns = csv.NewFilePatternMap(files)
+	}
+
+	setFormat(filePatterns, p.format)
+
+	parserOpts := []csv.Option{csv.WithPolicy(p.policy)}
+	if p.merge != "" {
+		parserOpts = append(parserOpts, csv.WithMerge(csv.MergePrecedence(p.merge)))
+	}
+	if p.provenance != "" {
+		parserOpts = append(parserOpts, csv.WithProvenance(csv.ProvenanceMode(p.provenance)))
+	}
+
+	parser, err := csv.NewParser(filePatterns, append(parserOpts, opts...)...)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"event":  "create_csv_parser_error",
+			"reason": err,
+		}).Error("could not create a parser with given configurations")
+		return nil, nil, exitUsage
+	}
+
+	return parser, files, exitOK
+}
+
+// setFormat sets the format of the file patterns without one, when the format is not empty.
+func setFormat(patterns map[string]*csv.FilePattern, format string) {
+	if format == "" {
+		return
+	}
+
+	for _, pattern := range patterns {
+		if pattern.Format == "" {
+			pattern.Format = csv.Format(format)
+		}
+	}
+}
+
+// newFlagSet creates a flag.FlagSet for a command, with a usage message showing the command description.
+func newFlagSet(name, args, description string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s %s %s\n\n%s\n\nFlags:\n", binaryName(), name, args, description)
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+// parseFlags parses the args of a command, returning false with the exit code when the command must not run.
+func parseFlags(fs *flag.FlagSet, args []string) (int, bool) {
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK, false
+		}
+		return exitUsage, false
+	}
+
+	return exitOK, true
+}
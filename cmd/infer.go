@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vsantosalmeida/csv-parser/usecase/csv"
+)
+
+func runInfer(args []string) int {
+	var files fileFlags
+	fs := newFlagSet("infer", "-f=roster1.csv,roster2.csv",
+		"Print a suggested FilePattern config for each file from its header, the output can be used with the -p flag.")
+	files.register(fs)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if files.empty() {
+		log.WithFields(log.Fields{
+			"event": "empty_files_arg",
+		}).Error("the `-f` arg is required to infer a file pattern and must not be empty")
+		fs.Usage()
+		return exitUsage
+	}
+
+	names, err := files.expand()
+	if err != nil {
+		log.WithFields(log.Fields{
+			"event":  "invalid_files_arg",
+			"files":  files.files,
+			"reason": err,
+		}).Error("could not find the files of the `-f` arg")
+		return exitUsage
+	}
+
+	patterns, err := csv.InferFilePatternMap(names)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"event":  "infer_file_pattern_failed",
+			"reason": err,
+		}).Error("could not read the file header")
+		return exitFileFailure
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", " ")
+	if err := enc.Encode(patterns); err != nil {
+		log.WithFields(log.Fields{
+			"event":  "print_file_patterns_failed",
+			"reason": err,
+		}).Error()
+		return exitWriteFailure
+	}
+
+	return exitOK
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vsantosalmeida/csv-parser/usecase/job"
+)
+
+// defaultJobsDir is the default folder of the jobs of the serve and jobs commands.
+const defaultJobsDir = "jobs"
+
+func runJobs(args []string) int {
+	var (
+		dir    string
+		state  string
+		output string
+	)
+	fs := newFlagSet("jobs", "[-data=jobs] [id...]",
+		"List the jobs of the serve command, the most recent first, or print the jobs with the given IDs.\n"+
+			"The jobs are read from the data folder, so it works while the server is running.")
+	fs.StringVar(&dir, "data", defaultJobsDir, "Folder of the jobs")
+	fs.StringVar(&state, "state", "", "List only the jobs in the state (queued, running, succeeded, failed or cancelled)")
+	fs.StringVar(&output, "output", summaryText, `Format printed to stdout, "text" or "json"`)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if output != summaryText && output != summaryJSON {
+		log.WithFields(log.Fields{
+			"event":  "invalid_output_arg",
+			"output": output,
+		}).Error(`the "-output" arg must be "text" or "json"`)
+		return exitUsage
+	}
+
+	// the store is read directly, a job.Service would mark the jobs of a running server as interrupted.
+	store, err := job.NewFileStore(dir)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"event":  "open_job_store_error",
+			"reason": err,
+		}).Error("could not open the jobs folder")
+		return exitUsage
+	}
+
+	var jobs []*job.Job
+	if ids := fs.Args(); len(ids) != 0 {
+		for _, id := range ids {
+			j, err := store.Get(id)
+			if err != nil {
+				log.WithFields(log.Fields{
+					"event":  "get_job_error",
+					"job":    id,
+					"reason": err,
+				}).Error("could not read the job")
+				return exitUsage
+			}
+			jobs = append(jobs, j)
+		}
+	} else if jobs, err = store.List(); err != nil {
+		log.WithFields(log.Fields{
+			"event":  "list_jobs_error",
+			"reason": err,
+		}).Error("could not list the jobs")
+		return exitFileFailure
+	}
+
+	if state != "" {
+		filtered := jobs[:0]
+		for _, j := range jobs {
+			if string(j.State) == state {
+				filtered = append(filtered, j)
+			}
+		}
+		jobs = filtered
+	}
+
+	if err := printJobs(os.Stdout, output, jobs, len(fs.Args()) != 0); err != nil {
+		log.WithFields(log.Fields{
+			"event":  "print_jobs_failed",
+			"reason": err,
+		}).Error("could not print the jobs")
+		return exitWriteFailure
+	}
+
+	return exitOK
+}
+
+// printJobs writes the jobs to w, as a table or with the details of each job.
+func printJobs(w io.Writer, format string, jobs []*job.Job, details bool) error {
+	if format == summaryJSON {
+		return encodeJSON(w, jobs)
+	}
+
+	if details {
+		for i, j := range jobs {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			writeJobText(w, j)
+		}
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSTATE\tFILE\tROWS\tACCEPTED\tREJECTED\tCREATED")
+	for _, j := range jobs {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%d\t%s\n", j.ID, j.State, j.File,
+			j.Progress.Rows, j.Progress.Accepted, j.Progress.Rejected, j.CreatedAt.Format(time.RFC3339))
+	}
+	return tw.Flush()
+}
+
+func writeJobText(w io.Writer, j *job.Job) {
+	fmt.Fprintf(w, "id: %s\nstate: %s\nfile: %s\n", j.ID, j.State, j.File)
+	fmt.Fprintf(w, "progress: %d rows, %d accepted, %d rejected\n", j.Progress.Rows, j.Progress.Accepted, j.Progress.Rejected)
+	fmt.Fprintf(w, "created: %s\n", j.CreatedAt.Format(time.RFC3339))
+	if j.StartedAt != nil {
+		fmt.Fprintf(w, "started: %s\n", j.StartedAt.Format(time.RFC3339))
+	}
+	if j.FinishedAt != nil {
+		fmt.Fprintf(w, "finished: %s\n", j.FinishedAt.Format(time.RFC3339))
+	}
+	if j.Error != "" {
+		fmt.Fprintf(w, "error: %s\n", j.Error)
+	}
+	if j.Summary != nil {
+		writeSummaryText(w, j.Summary)
+	}
+}
@@ -1,57 +1,86 @@
 package main
 
 import (
-	"flag"
+	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
-	"github.com/vsantosalmeida/csv-parser/usecase/csv"
 )
 
 func init() {
 	log.SetFormatter(&log.JSONFormatter{})
-	log.SetOutput(os.Stdout)
+	log.SetOutput(os.Stderr)
 	log.SetLevel(log.InfoLevel)
 }
 
-func main() {
-	var f string
-	flag.StringVar(&f, "f", "", `Files names separated by ","`)
-	flag.Parse()
+type command struct {
+	name        string
+	description string
+	run         func(args []string) int
+}
 
-	if strings.Trim(f, " ") == "" {
-		log.WithFields(log.Fields{
-			"event": "empty_files_arg",
-		}).Panic("the `-f` arg is required to process a file and must not be empty")
-	}
+var commands = []command{
+	{name: "parse", description: "Process the CSV files and write the result files", run: runParse},
+	{name: "validate", description: "Process the CSV files without writing any result file", run: runValidate},
+	{name: "infer", description: "Print a suggested file patterns config from the files' headers", run: runInfer},
+	{name: "diff", description: "Print the employees added, removed and changed between two result files", run: runDiff},
+	{name: "convert", description: "Write a result file again in another format", run: runConvert},
+	{name: "watch", description: "Watch a folder and process each new file", run: runWatch},
+	{name: "serve", description: "Serve the HTTP API to upload and parse files", run: runServe},
+	{name: "jobs", description: "List the jobs of the HTTP API or print a job", run: runJobs},
+}
 
-	files := strings.Split(f, ",")
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
 
-	filePatterns := csv.NewFilePatternMap(files)
+func run(args []string) int {
+	if len(args) == 0 {
+		usage()
+		return exitUsage
+	}
 
-	parser, err := csv.NewParser(filePatterns)
-	if err != nil {
+	switch name := args[0]; {
+	case name == "-h" || name == "-help" || name == "--help" || name == "help":
+		usage()
+		return exitOK
+	case strings.HasPrefix(name, "-"):
+		// keeps the flags without a command, like -f=roster1.csv, working as the parse command.
+		return runParse(args)
+	default:
+		for _, cmd := range commands {
+			if cmd.name == name {
+				return cmd.run(args[1:])
+			}
+		}
 		log.WithFields(log.Fields{
-			"event":  "create_csv_parser_error",
-			"reason": err,
-		}).Panic("could not create a parser with given configurations")
+			"event":   "unknown_command",
+			"command": name,
+		}).Error("unknown command")
+		usage()
+		return exitUsage
 	}
+}
 
-	errs := parser.ParseFiles(files)
-	if len(errs) != 0 {
-		for k, v := range errs {
-			log.WithFields(log.Fields{
-				"event":     "parse_file_finished_with_errors",
-				"error_key": k,
-				"reason":    v,
-			}).Warn()
-		}
-		return
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [flags]\n\nCommands:\n", binaryName())
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.description)
 	}
+	fmt.Fprintf(os.Stderr, `
+Run "%s <command> -h" to see the flags of a command.
+
+Exit codes:
+  %d  all the files were processed without bad data
+  %d  invalid args or file patterns
+  %d  some lines were written to the bad data file
+  %d  a file could not be processed or was rejected by the run policy
+  %d  a result file could not be written
+`, binaryName(), exitOK, exitUsage, exitBadData, exitFileFailure, exitWriteFailure)
+}
 
-	log.WithFields(log.Fields{
-		"event": "all_files_processed",
-		"files": files,
-	}).Info()
+func binaryName() string {
+	return filepath.Base(os.Args[0])
 }
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vsantosalmeida/csv-parser/usecase/csv"
+)
+
+func runParse(args []string) int {
+	var (
+		flags  parserFlags
+		sinks  sinkFlags
+		dryRun bool
+		diff   string
+	)
+	fs := newFlagSet("parse", "-f=roster1.csv,roster2.csv [flags]",
+		"Process the CSV files and write the employees and bad data result files.")
+	flags.register(fs)
+	sinks.register(fs)
+	fs.BoolVar(&dryRun, "dry-run", false, "Process the files without writing any result file, printing a report like the validate command")
+	fs.StringVar(&diff, "diff", "", "Previous employees result file (json or ndjson) to compare by ID, writing the added, removed and changed employees to a diff file")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if dryRun {
+		parser, files, code := flags.parser(fs)
+		if code != exitOK {
+			return code
+		}
+		return runDryRun(parser, files, flags.summary)
+	}
+
+	opts, closeSinks, ok := openSinks(&sinks)
+	if !ok {
+		return exitUsage
+	}
+	defer closeSinks()
+
+	if diff != "" {
+		previous, err := csv.LoadEmployeesFile(diff)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"event":  "load_previous_employees_error",
+				"file":   diff,
+				"reason": err,
+			}).Error("could not load the previous employees file of the `-diff` arg")
+			return exitUsage
+		}
+		opts = append(opts, csv.WithDiff(previous))
+	}
+
+	parser, files, code := flags.parser(fs, opts...)
+	if code != exitOK {
+		return code
+	}
+
+	errs := parser.ParseFiles(files)
+	logParseErrors(errs)
+
+	code = exitCode(parser.Summary(), errs)
+	if err := printSummary(os.Stdout, flags.summary, parser.Summary(), code); err != nil {
+		log.WithFields(log.Fields{
+			"event":  "print_summary_failed",
+			"reason": err,
+		}).Error()
+	}
+
+	if code == exitOK {
+		log.WithFields(log.Fields{
+			"event": "all_files_processed",
+			"files": files,
+		}).Info()
+	}
+
+	return code
+}
+
+func logParseErrors(errs map[string]error) {
+	for k, v := range errs {
+		log.WithFields(log.Fields{
+			"event":     "parse_file_finished_with_errors",
+			"error_key": k,
+			"reason":    v,
+		}).Warn()
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vsantosalmeida/csv-parser/api/handler"
+	"github.com/vsantosalmeida/csv-parser/api/rpc"
+	"github.com/vsantosalmeida/csv-parser/usecase/csv"
+	"github.com/vsantosalmeida/csv-parser/usecase/job"
+	"google.golang.org/grpc"
+)
+
+// shutdownTimeout is the time the running requests have to finish after the process is interrupted.
+const shutdownTimeout = 10 * time.Second
+
+func runServe(args []string) int {
+	var (
+		addr     string
+		grpcAddr string
+		config   job.Config
+		api      handler.Config
+	)
+	fs := newFlagSet("serve", "[-addr=:8080] [flags]",
+		"Serve the HTTP API, parsing each uploaded file in background as a job with its file, result files and metadata\n"+
+			"kept in a subfolder of the data folder.")
+	fs.StringVar(&addr, "addr", ":8080", "Address the server listens on")
+	fs.StringVar(&grpcAddr, "grpc", "", `Address the gRPC Parser service listens on, like ":9090", not served when empty`)
+	fs.StringVar(&config.Dir, "data", defaultJobsDir, "Folder of the jobs")
+	fs.IntVar(&config.Workers, "workers", 1, "Max number of jobs running at the same time")
+	fs.Int64Var(&api.MaxUploadSize, "max-upload", 32<<20, "Max size in bytes of an uploaded file")
+	registerPolicy(fs, &config.Policy)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	svc, err := newJobService(config)
+	if err != nil {
+		return exitUsage
+	}
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           handler.NewJobHandler(svc, api),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if grpcAddr != "" {
+		grpcServer, err := serveGRPC(grpcAddr, config.Policy)
+		if err != nil {
+			svc.Close()
+			return exitUsage
+		}
+		defer grpcServer.GracefulStop()
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	log.WithFields(log.Fields{
+		"event": "serve_started",
+		"addr":  addr,
+	}).Info()
+	err = server.ListenAndServe()
+	// the jobs not finished are cancelled, they would be marked as interrupted by the next process anyway.
+	svc.Close()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.WithFields(log.Fields{
+			"event":  "serve_failed",
+			"reason": err,
+		}).Error("could not serve the API")
+		return exitFileFailure
+	}
+
+	return exitOK
+}
+
+// serveGRPC serves the gRPC Parser service on addr in background.
+func serveGRPC(addr string, policy csv.Policy) (*grpc.Server, error) {
+	server, err := rpc.NewServer(policy)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"event":  "create_grpc_server_error",
+			"reason": err,
+		}).Error("could not create the gRPC server")
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"event":  "grpc_listen_failed",
+			"addr":   addr,
+			"reason": err,
+		}).Error("could not listen on the gRPC address")
+		return nil, err
+	}
+
+	log.WithFields(log.Fields{
+		"event": "grpc_serve_started",
+		"addr":  addr,
+	}).Info()
+	go func() {
+		if err := server.Serve(listener); err != nil {
+			log.WithFields(log.Fields{
+				"event":  "grpc_serve_failed",
+				"reason": err,
+			}).Error("could not serve the gRPC service")
+		}
+	}()
+
+	return server, nil
+}
+
+// newJobService creates the job.Service with a job.FileStore on the jobs folder.
+func newJobService(config job.Config) (*job.Service, error) {
+	store, err := job.NewFileStore(config.Dir)
+	if err == nil {
+		var svc *job.Service
+		if svc, err = job.NewService(config, store); err == nil {
+			return svc, nil
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"event":  "create_job_service_error",
+		"reason": err,
+	}).Error("could not create the job service with given configurations")
+	return nil, err
+}
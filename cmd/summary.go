@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/vsantosalmeida/csv-parser/usecase/csv"
+)
+
+const (
+	summaryText = "text"
+	summaryJSON = "json"
+)
+
+type runSummary struct {
+	*csv.Summary
+	ExitCode int `json:"exit_code"`
+}
+
+type runReport struct {
+	*csv.Report
+	ExitCode int `json:"exit_code"`
+}
+
+// printSummary writes the run summary to w.
+func printSummary(w io.Writer, format string, summary *csv.Summary, code int) error {
+	if format == summaryJSON {
+		return encodeJSON(w, runSummary{Summary: summary, ExitCode: code})
+	}
+
+	writeSummaryText(w, summary)
+	_, err := fmt.Fprintf(w, "exit code: %d\n", code)
+	return err
+}
+
+// printReport writes the dry-run report to w, with the bad lines counters and samples of each file.
+func printReport(w io.Writer, format string, report *csv.Report, code int) error {
+	if format == summaryJSON {
+		return encodeJSON(w, runReport{Report: report, ExitCode: code})
+	}
+
+	writeSummaryText(w, report.Summary)
+
+	if len(report.Reasons) != 0 {
+		fmt.Fprintln(w, "reasons:")
+		writeCounters(w, "  ", report.Reasons)
+	}
+
+	files := make([]string, 0, len(report.Files))
+	for file := range report.Files {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		fileReport := report.Files[file]
+		fmt.Fprintf(w, "%s: %d lines, %d bad\n", file, fileReport.Lines, fileReport.BadLines)
+
+		if len(fileReport.Columns) != 0 {
+			fmt.Fprintln(w, "  columns:")
+			writeCounters(w, "    ", fileReport.Columns)
+		}
+
+		if len(fileReport.Samples) != 0 {
+			fmt.Fprintln(w, "  samples:")
+			for _, line := range fileReport.Samples {
+				fmt.Fprintf(w, "    line %s: %s\n", line.Line, strings.Join(line.Reasons, "; "))
+			}
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "exit code: %d\n", code)
+	return err
+}
+
+func writeSummaryText(w io.Writer, summary *csv.Summary) {
+	if summary.RunID != "" {
+		fmt.Fprintf(w, "run: %s\n", summary.RunID)
+	}
+	fmt.Fprintf(w, "files: %d\n", summary.Files)
+	fmt.Fprintf(w, "employees: %d", summary.Employees)
+	if summary.EmployeesFile != "" {
+		fmt.Fprintf(w, " (%s)", summary.EmployeesFile)
+	}
+	fmt.Fprintf(w, "\nbad lines: %d", summary.BadLines)
+	if summary.BadDataFile != "" {
+		fmt.Fprintf(w, " (%s)", summary.BadDataFile)
+	}
+	fmt.Fprintln(w)
+	if summary.ProvenanceFile != "" {
+		fmt.Fprintf(w, "provenance: %s\n", summary.ProvenanceFile)
+	}
+	if summary.LineageFile != "" {
+		fmt.Fprintf(w, "merged: %d (%s)\n", summary.Merged, summary.LineageFile)
+	}
+	if diff := summary.Diff; diff != nil {
+		fmt.Fprintf(w, "diff: %d added, %d removed, %d changed, %d unchanged (%s)\n",
+			diff.Added, diff.Removed, diff.Changed, diff.Unchanged, diff.File)
+	}
+
+	if len(summary.Errors) != 0 {
+		keys := make([]string, 0, len(summary.Errors))
+		for k := range summary.Errors {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		fmt.Fprintf(w, "errors: %d\n", len(keys))
+		for _, k := range keys {
+			fmt.Fprintf(w, "  %s: %s\n", k, summary.Errors[k])
+		}
+	}
+}
+
+// writeCounters writes each counter in a line, from the highest to the lowest value.
+func writeCounters(w io.Writer, indent string, counters map[string]int) {
+	keys := make([]string, 0, len(counters))
+	for k := range counters {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counters[keys[i]] != counters[keys[j]] {
+			return counters[keys[i]] > counters[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	for _, k := range keys {
+		fmt.Fprintf(w, "%s%d  %s\n", indent, counters[k], k)
+	}
+}
+
+func encodeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", " ")
+	return enc.Encode(v)
+}
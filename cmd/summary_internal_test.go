@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vsantosalmeida/csv-parser/usecase/csv"
+)
+
+var givenSummary = &csv.Summary{
+	Files:         2,
+	Employees:     3,
+	BadLines:      1,
+	EmployeesFile: "employee-20220101000000.json",
+	BadDataFile:   "badData-20220101000000.json",
+	Errors: map[string]string{
+		"roster2.csv": "open roster2.csv: the file could not be opened",
+		"roster1.csv": "parse roster1.csv: the run was aborted",
+	},
+}
+
+func TestPrintSummary_Text(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, printSummary(&buf, summaryText, givenSummary, exitFileFailure))
+
+	want := "files: 2\n" +
+		"employees: 3 (employee-20220101000000.json)\n" +
+		"bad lines: 1 (badData-20220101000000.json)\n" +
+		"errors: 2\n" +
+		"  roster1.csv: parse roster1.csv: the run was aborted\n" +
+		"  roster2.csv: open roster2.csv: the file could not be opened\n" +
+		"exit code: 4\n"
+	assert.Equal(t, want, buf.String())
+}
+
+func TestPrintSummary_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, printSummary(&buf, summaryJSON, givenSummary, exitFileFailure))
+
+	var got map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, map[string]interface{}{
+		"files":          float64(2),
+		"employees":      float64(3),
+		"bad_lines":      float64(1),
+		"employees_file": "employee-20220101000000.json",
+		"bad_data_file":  "badData-20220101000000.json",
+		"errors": map[string]interface{}{
+			"roster1.csv": "parse roster1.csv: the run was aborted",
+			"roster2.csv": "open roster2.csv: the file could not be opened",
+		},
+		"exit_code": float64(4),
+	}, got)
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vsantosalmeida/csv-parser/usecase/csv"
+)
+
+func runValidate(args []string) int {
+	var flags parserFlags
+	fs := newFlagSet("validate", "-f=roster1.csv,roster2.csv [flags]",
+		"Process the CSV files without writing any result file, printing a report with the bad data of each file.")
+	flags.register(fs)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	parser, files, code := flags.parser(fs)
+	if code != exitOK {
+		return code
+	}
+
+	return runDryRun(parser, files, flags.summary)
+}
+
+// dryRun validates the files with the parser and prints the report, used by the validate command
+// and the parse command with the -dry-run flag.
+func runDryRun(parser csv.Parser, files []string, format string) int {
+	report, errs := parser.Validate(files)
+	logParseErrors(errs)
+
+	code := exitCode(report.Summary, errs)
+	if err := printReport(os.Stdout, format, report, code); err != nil {
+		log.WithFields(log.Fields{
+			"event":  "print_report_failed",
+			"reason": err,
+		}).Error()
+	}
+
+	return code
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vsantosalmeida/csv-parser/usecase/csv"
+	"github.com/vsantosalmeida/csv-parser/usecase/watch"
+)
+
+func runWatch(args []string) int {
+	var (
+		config   watch.Config
+		patterns string
+		format   string
+		policy   csv.Policy
+	)
+	fs := newFlagSet("watch", "-inbox=drop -p=patterns.json [flags]",
+		"Watch the inbox folder, processing each new file once it is fully written and moving it to the processed or failed folder.\n"+
+			"The result files of each file are written to a subfolder of the results folder, until the process is interrupted.")
+	fs.StringVar(&config.Inbox, "inbox", "", "Folder watched for new files")
+	fs.StringVar(&config.Processed, "processed", "", `Folder of the processed files, "<inbox>/processed" when empty`)
+	fs.StringVar(&config.Failed, "failed", "", `Folder of the files finished with errors, "<inbox>/failed" when empty`)
+	fs.StringVar(&config.Results, "results", "", `Folder of the result files, "<inbox>/results" when empty`)
+	fs.DurationVar(&config.Interval, "interval", 5*time.Second, "Time between each scan of the inbox")
+	fs.DurationVar(&config.StableFor, "stable", 10*time.Second, "Time a file must keep its size to be processed")
+	fs.StringVar(&config.Marker, "marker", "", `Suffix of the marker files, like ".done", a file is only processed after its marker is created`)
+	fs.StringVar(&patterns, "p", "", "JSON file with the FilePattern of each file, globs like \"*.csv\" can be used as names")
+	fs.StringVar(&format, "format", "", "Format of the input files without a format in the file patterns (csv, xlsx, json, ndjson, xml or fixed), from each file extension when empty")
+	registerPolicy(fs, &policy)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if config.Inbox == "" || patterns == "" {
+		log.WithFields(log.Fields{
+			"event": "empty_watch_args",
+		}).Error("the `-inbox` and `-p` args are required to watch a folder")
+		fs.Usage()
+		return exitUsage
+	}
+	for dir, name := range map[*string]string{&config.Processed: "processed", &config.Failed: "failed", &config.Results: "results"} {
+		if *dir == "" {
+			*dir = filepath.Join(config.Inbox, name)
+		}
+	}
+
+	filePatterns, err := csv.LoadFilePatternMap(patterns)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"event":  "load_file_patterns_error",
+			"file":   patterns,
+			"reason": err,
+		}).Error("could not load the file patterns")
+		return exitUsage
+	}
+	setFormat(filePatterns, format)
+
+	// the config is validated before watching the folder.
+	if _, err := csv.NewParser(filePatterns, csv.WithPolicy(policy)); err != nil {
+		log.WithFields(log.Fields{
+			"event":  "create_csv_parser_error",
+			"reason": err,
+		}).Error("could not create a parser with given configurations")
+		return exitUsage
+	}
+
+	svc, err := watch.NewService(config, func(outputDir string) (csv.Parser, error) {
+		return csv.NewParser(filePatterns, csv.WithPolicy(policy), csv.WithOutputDir(outputDir))
+	})
+	if err != nil {
+		log.WithFields(log.Fields{
+			"event":  "create_watch_error",
+			"reason": err,
+		}).Error("could not watch the inbox folder")
+		return exitUsage
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := svc.Run(ctx); err != nil {
+		return exitFileFailure
+	}
+
+	return exitOK
+}
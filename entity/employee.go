@@ -1,13 +1,86 @@
 package entity
 
-import "fmt"
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+)
 
 type Employee struct {
-	ID     string  `json:"id"`
-	Email  string  `json:"email"`
-	Name   string  `json:"name"`
-	Salary float64 `json:"salary"`
-	Phone  string  `json:"phone,omitempty"`
+	ID     string  `json:"id" xml:"id"`
+	Email  string  `json:"email" xml:"email"`
+	Name   string  `json:"name" xml:"name"`
+	Salary float64 `json:"salary" xml:"salary"`
+	Phone  string  `json:"phone,omitempty" xml:"phone,omitempty"`
+	// Attributes are the custom attributes mapped from the extra columns of the file.
+	Attributes Attributes `json:"attributes,omitempty" xml:"attributes,omitempty"`
+	// Meta is the Provenance of the employee, only set when the run records it.
+	Meta *Provenance `json:"_meta,omitempty" xml:"-"`
+}
+
+// Attributes holds the custom attributes of an Employee by name, each value is a string, a float64,
+// a bool or a date formatted as "2006-01-02".
+type Attributes map[string]interface{}
+
+// Names returns the names of the attributes sorted.
+func (a Attributes) Names() []string {
+	names := make([]string, 0, len(a))
+	for name := range a {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// MarshalXML writes each attribute as an element with its name, sorted by name:
+//
+//	<attributes><attribute name="department">Sales</attribute></attributes>
+func (a Attributes) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type attribute struct {
+		Name  string `xml:"name,attr"`
+		Value string `xml:",chardata"`
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, name := range a.Names() {
+		element := xml.StartElement{Name: xml.Name{Local: "attribute"}}
+		if err := e.EncodeElement(attribute{Name: name, Value: FormatAttribute(a[name])}, element); err != nil {
+			return err
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+// FormatAttribute returns an attribute value as text, an empty string for a missing attribute.
+func FormatAttribute(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Provenance holds where an Employee was read from.
+type Provenance struct {
+	RunID string `json:"run_id"`
+	File  string `json:"file"`
+	Line  int    `json:"line"`
+	// Columns maps each field, named like its JSON key, to the source columns, the name can use two.
+	Columns map[string][]string `json:"columns"`
+	// Sources holds the Provenance of each file of an employee merged from several files.
+	Sources []*Provenance `json:"sources,omitempty"`
 }
 
 func BuildEmployeeName(firstName, lastName string) string {
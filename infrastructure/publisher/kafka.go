@@ -0,0 +1,44 @@
+package publisher
+
+import (
+	"context"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaWriter is the method of *kafka.Writer used by the KafkaPublisher.
+type KafkaWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// KafkaPublisher is a Publisher writing each Message to its Kafka topic with the Key as the message key,
+// so the messages of an employee go to the same partition.
+//
+// The *kafka.Writer must not have a Topic and must use kafka.RequireAll acks for the at-least-once delivery.
+type KafkaPublisher struct {
+	writer KafkaWriter
+}
+
+// NewKafkaPublisher returns a KafkaPublisher writing with the writer.
+func NewKafkaPublisher(writer KafkaWriter) *KafkaPublisher {
+	return &KafkaPublisher{writer: writer}
+}
+
+func (p *KafkaPublisher) Publish(ctx context.Context, msgs []*Message) error {
+	kafkaMsgs := make([]kafka.Message, 0, len(msgs))
+	for _, msg := range msgs {
+		headers := make([]kafka.Header, 0, len(msg.Headers))
+		for k, v := range msg.Headers {
+			headers = append(headers, kafka.Header{Key: k, Value: []byte(v)})
+		}
+
+		kafkaMsgs = append(kafkaMsgs, kafka.Message{
+			Topic:   msg.Topic,
+			Key:     []byte(msg.Key),
+			Value:   msg.Value,
+			Headers: headers,
+		})
+	}
+
+	return p.writer.WriteMessages(ctx, kafkaMsgs...)
+}
@@ -0,0 +1,37 @@
+package publisher_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/vsantosalmeida/csv-parser/infrastructure/publisher"
+)
+
+type fakeKafkaWriter struct {
+	msgs []kafka.Message
+}
+
+func (f *fakeKafkaWriter) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	f.msgs = append(f.msgs, msgs...)
+	return nil
+}
+
+func TestKafkaPublisher_Publish(t *testing.T) {
+	writer := &fakeKafkaWriter{}
+	err := publisher.NewKafkaPublisher(writer).Publish(context.Background(), []*publisher.Message{
+		{Topic: "employees", Key: "1", Value: []byte(`{}`), Headers: map[string]string{publisher.HeaderType: publisher.TypeEmployee}},
+	})
+	assert.NoError(t, err)
+
+	want := []kafka.Message{
+		{
+			Topic:   "employees",
+			Key:     []byte("1"),
+			Value:   []byte(`{}`),
+			Headers: []kafka.Header{{Key: publisher.HeaderType, Value: []byte(publisher.TypeEmployee)}},
+		},
+	}
+	assert.Equal(t, want, writer.msgs)
+}
@@ -0,0 +1,43 @@
+package publisher
+
+import (
+	"context"
+
+	"github.com/nats-io/nats.go"
+)
+
+// HeaderKey is the NATS header with the Key of the Message, since NATS messages have no key.
+const HeaderKey = "key"
+
+// JetStream is the method of nats.JetStreamContext used by the NATSPublisher.
+type JetStream interface {
+	PublishMsg(m *nats.Msg, opts ...nats.PubOpt) (*nats.PubAck, error)
+}
+
+// NATSPublisher is a Publisher sending each Message to the JetStream subject of its Topic, with the Key in
+// the HeaderKey header. Each message is acknowledged by the stream before the next one is sent.
+type NATSPublisher struct {
+	js JetStream
+}
+
+// NewNATSPublisher returns a NATSPublisher sending with the js, a stream must capture the subjects.
+func NewNATSPublisher(js JetStream) *NATSPublisher {
+	return &NATSPublisher{js: js}
+}
+
+func (p *NATSPublisher) Publish(ctx context.Context, msgs []*Message) error {
+	for _, msg := range msgs {
+		natsMsg := nats.NewMsg(msg.Topic)
+		natsMsg.Data = msg.Value
+		natsMsg.Header.Set(HeaderKey, msg.Key)
+		for k, v := range msg.Headers {
+			natsMsg.Header.Set(k, v)
+		}
+
+		if _, err := p.js.PublishMsg(natsMsg, nats.Context(ctx)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package publisher_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/vsantosalmeida/csv-parser/infrastructure/publisher"
+)
+
+type fakeJetStream struct {
+	msgs []*nats.Msg
+	err  error
+}
+
+func (f *fakeJetStream) PublishMsg(m *nats.Msg, _ ...nats.PubOpt) (*nats.PubAck, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	f.msgs = append(f.msgs, m)
+	return &nats.PubAck{Stream: "EMPLOYEES", Sequence: uint64(len(f.msgs))}, nil
+}
+
+func TestNATSPublisher_Publish(t *testing.T) {
+	js := &fakeJetStream{}
+	err := publisher.NewNATSPublisher(js).Publish(context.Background(), []*publisher.Message{
+		{Topic: "employees", Key: "1", Value: []byte(`{}`), Headers: map[string]string{publisher.HeaderType: publisher.TypeEmployee}},
+	})
+	assert.NoError(t, err)
+
+	if assert.Len(t, js.msgs, 1) {
+		assert.Equal(t, "employees", js.msgs[0].Subject)
+		assert.Equal(t, []byte(`{}`), js.msgs[0].Data)
+		assert.Equal(t, "1", js.msgs[0].Header.Get(publisher.HeaderKey))
+		assert.Equal(t, publisher.TypeEmployee, js.msgs[0].Header.Get(publisher.HeaderType))
+	}
+
+	js.err = errors.New("no stream")
+	assert.Error(t, publisher.NewNATSPublisher(js).Publish(context.Background(), []*publisher.Message{{Topic: "employees"}}))
+}
@@ -0,0 +1,256 @@
+package publisher
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vsantosalmeida/csv-parser/entity"
+	"github.com/vsantosalmeida/csv-parser/usecase/csv"
+)
+
+// Types of the events, set in the HeaderType of each Message.
+const (
+	TypeEmployee = "employee"
+	TypeBadData  = "bad_data"
+	TypeSummary  = "run_summary"
+)
+
+// Headers set in each Message.
+const (
+	HeaderType  = "type"
+	HeaderRunID = "run_id"
+)
+
+const (
+	defaultBatchSize   = 100
+	defaultMaxAttempts = 5
+	defaultBackoff     = 200 * time.Millisecond
+)
+
+// Message is an event published to a topic, or subject, keyed by the employee ID.
+type Message struct {
+	Topic   string
+	Key     string
+	Value   []byte
+	Headers map[string]string
+}
+
+// Publisher sends messages to a broker, it must only return when all the messages were acknowledged,
+// a failure may have published part of the messages.
+type Publisher interface {
+	Publish(ctx context.Context, msgs []*Message) error
+}
+
+// Config configures the topics and the delivery of the Sink, the empty values use the defaults.
+type Config struct {
+	// EmployeesTopic receives a message by employee, "employees" by default.
+	EmployeesTopic string
+	// BadDataTopic receives a message by bad line, "bad_data" by default.
+	BadDataTopic string
+	// SummaryTopic receives the summary of each run, "runs" by default.
+	SummaryTopic string
+	// BatchSize is the max messages of each Publish call, 100 by default.
+	BatchSize int
+	// MaxAttempts is the max calls of Publish for a batch, 5 by default.
+	MaxAttempts int
+	// Backoff is the wait before the second attempt, doubled after each failure, 200ms by default.
+	Backoff time.Duration
+}
+
+// EmployeeEvent is the value of the messages of the EmployeesTopic.
+type EmployeeEvent struct {
+	RunID    string           `json:"run_id"`
+	File     string           `json:"file"`
+	Employee *entity.Employee `json:"employee"`
+}
+
+// BadDataEvent is the value of the messages of the BadDataTopic.
+type BadDataEvent struct {
+	RunID   string      `json:"run_id"`
+	File    string      `json:"file"`
+	ID      string      `json:"id,omitempty"`
+	Line    json.Number `json:"line"`
+	Reasons []string    `json:"reasons"`
+}
+
+// SummaryEvent is the value of the messages of the SummaryTopic, the Summary RunID is the ID of the run.
+type SummaryEvent struct {
+	*csv.Summary
+}
+
+// Sink is a csv.SummarySink and csv.RunSink publishing a message by accepted employee and by bad line, keyed by
+// the employee ID, and a message with the Summary when the run finishes.
+//
+// The delivery is at-least-once, a failed batch is published again so the consumers may receive duplicates.
+type Sink struct {
+	publisher Publisher
+	config    Config
+	// runID identifies the messages of a run, the run ID of the parser set by StartRun, or created by the first
+	// message when the parser has none. It is cleared by WriteSummary.
+	runID string
+	sleep func(time.Duration)
+}
+
+// NewSink returns a Sink publishing with the Publisher.
+func NewSink(publisher Publisher, config Config) *Sink {
+	setDefault(&config.EmployeesTopic, "employees")
+	setDefault(&config.BadDataTopic, "bad_data")
+	setDefault(&config.SummaryTopic, "runs")
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultBatchSize
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaultMaxAttempts
+	}
+	if config.Backoff <= 0 {
+		config.Backoff = defaultBackoff
+	}
+
+	return &Sink{
+		publisher: publisher,
+		config:    config,
+		sleep:     time.Sleep,
+	}
+}
+
+// StartRun sets the ID of the messages of the run, the parser's run ID with csv.WithProvenance.
+func (s *Sink) StartRun(runID string) {
+	s.runID = runID
+}
+
+// WriteFile publishes the employees and then the bad data of the file, the bad lines without an ID are keyed
+// by "<file>:<line>".
+func (s *Sink) WriteFile(file string, employees []*entity.Employee, badData []*csv.BadData) error {
+	runID, err := s.currentRunID()
+	if err != nil {
+		return err
+	}
+
+	msgs := make([]*Message, 0, len(employees)+len(badData))
+	for _, e := range employees {
+		msg, err := s.message(s.config.EmployeesTopic, e.ID, TypeEmployee, runID, EmployeeEvent{RunID: runID, File: file, Employee: e})
+		if err != nil {
+			return err
+		}
+		msgs = append(msgs, msg)
+	}
+
+	for _, line := range badData {
+		key := line.ID
+		if key == "" {
+			key = fmt.Sprintf("%s:%s", file, line.Line)
+		}
+		event := BadDataEvent{RunID: runID, File: file, ID: line.ID, Line: line.Line, Reasons: line.Reasons}
+		msg, err := s.message(s.config.BadDataTopic, key, TypeBadData, runID, event)
+		if err != nil {
+			return err
+		}
+		msgs = append(msgs, msg)
+	}
+
+	if err := s.publish(msgs); err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"event":     "publisher_sink_file_written",
+		"file":      file,
+		"run_id":    runID,
+		"employees": len(employees),
+		"bad_lines": len(badData),
+	}).Info()
+
+	return nil
+}
+
+// WriteSummary publishes the Summary keyed by the run ID, the next WriteFile starts a new run.
+func (s *Sink) WriteSummary(summary *csv.Summary) error {
+	if summary.RunID != "" {
+		s.runID = summary.RunID
+	}
+	runID, err := s.currentRunID()
+	if err != nil {
+		return err
+	}
+	s.runID = ""
+
+	event := *summary
+	event.RunID = runID
+	msg, err := s.message(s.config.SummaryTopic, runID, TypeSummary, runID, SummaryEvent{Summary: &event})
+	if err != nil {
+		return err
+	}
+
+	return s.publish([]*Message{msg})
+}
+
+func (s *Sink) message(topic, key, eventType, runID string, event interface{}) (*Message, error) {
+	value, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Message{
+		Topic: topic,
+		Key:   key,
+		Value: value,
+		Headers: map[string]string{
+			HeaderType:  eventType,
+			HeaderRunID: runID,
+		},
+	}, nil
+}
+
+// publish sends the messages in batches, each batch is retried with an exponential backoff.
+func (s *Sink) publish(msgs []*Message) error {
+	for start := 0; start < len(msgs); start += s.config.BatchSize {
+		end := start + s.config.BatchSize
+		if end > len(msgs) {
+			end = len(msgs)
+		}
+
+		backoff := s.config.Backoff
+		for attempt := 1; ; attempt++ {
+			err := s.publisher.Publish(context.Background(), msgs[start:end])
+			if err == nil {
+				break
+			}
+			if attempt == s.config.MaxAttempts {
+				return fmt.Errorf("could not publish %d messages after %d attempts: %w", end-start, attempt, err)
+			}
+
+			log.WithFields(log.Fields{
+				"event":   "publish_failed",
+				"attempt": attempt,
+				"reason":  err,
+			}).Warn("could not publish the messages, retrying")
+			s.sleep(backoff)
+			backoff *= 2
+		}
+	}
+
+	return nil
+}
+
+func (s *Sink) currentRunID() (string, error) {
+	if s.runID == "" {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+		s.runID = hex.EncodeToString(b)
+	}
+
+	return s.runID, nil
+}
+
+func setDefault(value *string, def string) {
+	if *value == "" {
+		*value = def
+	}
+}
@@ -0,0 +1,164 @@
+package publisher_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/vsantosalmeida/csv-parser/entity"
+	"github.com/vsantosalmeida/csv-parser/infrastructure/publisher"
+	"github.com/vsantosalmeida/csv-parser/usecase/csv"
+)
+
+func init() {
+	log.SetOutput(ioutil.Discard)
+}
+
+// fakeBroker is an in-process Publisher failing the first calls, keeping the published messages.
+type fakeBroker struct {
+	failures int
+	calls    int
+	msgs     []*publisher.Message
+}
+
+func (f *fakeBroker) Publish(_ context.Context, msgs []*publisher.Message) error {
+	f.calls++
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("broker unavailable")
+	}
+
+	f.msgs = append(f.msgs, msgs...)
+	return nil
+}
+
+func (f *fakeBroker) topic(topic string) []*publisher.Message {
+	var msgs []*publisher.Message
+	for _, msg := range f.msgs {
+		if msg.Topic == topic {
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs
+}
+
+func TestSink_ParseFiles(t *testing.T) {
+	broker := &fakeBroker{failures: 2}
+	sink := publisher.NewSink(broker, publisher.Config{BatchSize: 2, Backoff: time.Millisecond})
+
+	dir, err := ioutil.TempDir("", "publisher")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	givenFile := "../../usecase/csv/test_files/roster1.csv"
+	parser, err := csv.NewParser(map[string]*csv.FilePattern{
+		givenFile: {
+			FirstNameColumn: "Name",
+			SalaryColumn:    "Wage",
+			EmailColumn:     "Email",
+			IDColumn:        "Number",
+		},
+	}, csv.WithSink(sink), csv.WithOutputDir(dir))
+	assert.NoError(t, err)
+	assert.Empty(t, parser.ParseFiles([]string{givenFile}))
+
+	// 3 batches of employees and bad data, 1 summary and the 2 failed calls retried.
+	assert.Equal(t, 6, broker.calls)
+
+	employees := broker.topic("employees")
+	if assert.Len(t, employees, 3) {
+		assert.Equal(t, "1", employees[0].Key)
+		assert.Equal(t, publisher.TypeEmployee, employees[0].Headers[publisher.HeaderType])
+
+		var event publisher.EmployeeEvent
+		assert.NoError(t, json.Unmarshal(employees[0].Value, &event))
+		assert.Equal(t, givenFile, event.File)
+		assert.Equal(t, "John Doe", event.Employee.Name)
+		assert.Equal(t, employees[0].Headers[publisher.HeaderRunID], event.RunID)
+	}
+
+	badData := broker.topic("bad_data")
+	if assert.Len(t, badData, 2) {
+		assert.Equal(t, "4", badData[0].Key)
+		assert.Equal(t, "5", badData[1].Key)
+	}
+
+	summaries := broker.topic("runs")
+	if assert.Len(t, summaries, 1) {
+		var event publisher.SummaryEvent
+		assert.NoError(t, json.Unmarshal(summaries[0].Value, &event))
+		assert.Equal(t, summaries[0].Key, event.RunID)
+		assert.Equal(t, employees[0].Headers[publisher.HeaderRunID], event.RunID)
+		assert.Equal(t, 3, event.Employees)
+		assert.Equal(t, 2, event.BadLines)
+	}
+}
+
+func TestSink_ParseFiles_Provenance(t *testing.T) {
+	broker := &fakeBroker{}
+	sink := publisher.NewSink(broker, publisher.Config{})
+
+	dir, err := ioutil.TempDir("", "publisher")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	givenFile := "../../usecase/csv/test_files/roster1.csv"
+	parser, err := csv.NewParser(map[string]*csv.FilePattern{
+		givenFile: {
+			FirstNameColumn: "Name",
+			SalaryColumn:    "Wage",
+			EmailColumn:     "Email",
+			IDColumn:        "Number",
+		},
+	}, csv.WithSink(sink), csv.WithOutputDir(dir), csv.WithProvenance(csv.ProvenanceEmbedded))
+	assert.NoError(t, err)
+	assert.Empty(t, parser.ParseFiles([]string{givenFile}))
+
+	runID := parser.Summary().RunID
+	assert.NotEmpty(t, runID)
+
+	employees := broker.topic("employees")
+	if assert.NotEmpty(t, employees) {
+		var event publisher.EmployeeEvent
+		assert.NoError(t, json.Unmarshal(employees[0].Value, &event))
+		assert.Equal(t, runID, event.RunID)
+		assert.Equal(t, runID, employees[0].Headers[publisher.HeaderRunID])
+		assert.Equal(t, runID, event.Employee.Meta.RunID)
+	}
+
+	summaries := broker.topic("runs")
+	if assert.Len(t, summaries, 1) {
+		var event publisher.SummaryEvent
+		assert.NoError(t, json.Unmarshal(summaries[0].Value, &event))
+		assert.Equal(t, runID, summaries[0].Key)
+		assert.Equal(t, runID, summaries[0].Headers[publisher.HeaderRunID])
+		assert.Equal(t, runID, event.RunID)
+	}
+}
+
+func TestSink_WriteFile_Error(t *testing.T) {
+	broker := &fakeBroker{failures: 3}
+	sink := publisher.NewSink(broker, publisher.Config{MaxAttempts: 3, Backoff: time.Millisecond})
+
+	givenEmployees := []*entity.Employee{{ID: "1", Email: "doe@test.com", Name: "John Doe", Salary: 10}}
+	assert.Error(t, sink.WriteFile("roster1.csv", givenEmployees, nil))
+	assert.Equal(t, 3, broker.calls)
+	assert.Empty(t, broker.msgs)
+}
+
+func TestSink_WriteFile_BadDataWithoutID(t *testing.T) {
+	broker := &fakeBroker{}
+	sink := publisher.NewSink(broker, publisher.Config{})
+
+	givenBadData := []*csv.BadData{{Line: "3", Reasons: []string{"the line could not be parsed"}}}
+	assert.NoError(t, sink.WriteFile("roster1.csv", nil, givenBadData))
+	if assert.Len(t, broker.msgs, 1) {
+		assert.Equal(t, "roster1.csv:3", broker.msgs[0].Key)
+	}
+}
@@ -0,0 +1,131 @@
+package sqlsink
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Dialect is the SQL flavor of the database, it sets the placeholders, the identifier quotes and the upsert syntax.
+type Dialect string
+
+// Dialects supported by the Sink.
+const (
+	DialectPostgres Dialect = "postgres"
+	DialectMySQL    Dialect = "mysql"
+	DialectSQLite   Dialect = "sqlite"
+)
+
+// identifier matches the table and column names, with an optional schema like "hr.employees".
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+func validateDialect(d Dialect) error {
+	switch d {
+	case DialectPostgres, DialectMySQL, DialectSQLite:
+		return nil
+	default:
+		return fmt.Errorf("unsupported dialect %q, must be postgres, mysql or sqlite", d)
+	}
+}
+
+// quote returns the identifier quoted by the dialect, each part of a schema qualified name is quoted.
+func (d Dialect) quote(name string) string {
+	q := `"`
+	if d == DialectMySQL {
+		q = "`"
+	}
+
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = q + part + q
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// placeholder returns the placeholder of the n-th argument, starting at 1.
+func (d Dialect) placeholder(n int) string {
+	if d == DialectPostgres {
+		return fmt.Sprintf("$%d", n)
+	}
+
+	return "?"
+}
+
+// values returns the VALUES list of rows with columns arguments each, like "(?, ?), (?, ?)".
+func (d Dialect) values(rows, columns int) string {
+	var b strings.Builder
+	n := 1
+	for i := 0; i < rows; i++ {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("(")
+		for j := 0; j < columns; j++ {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(d.placeholder(n))
+			n++
+		}
+		b.WriteString(")")
+	}
+
+	return b.String()
+}
+
+// upsert returns the statement inserting rows into table, updating the columns of the rows with an existing key.
+func (d Dialect) upsert(table, key string, columns []string, rows int) string {
+	quoted := make([]string, len(columns))
+	updates := make([]string, 0, len(columns)-1)
+	for i, column := range columns {
+		quoted[i] = d.quote(column)
+		if column == key {
+			continue
+		}
+
+		switch d {
+		case DialectMySQL:
+			updates = append(updates, fmt.Sprintf("%s = VALUES(%s)", quoted[i], quoted[i]))
+		default:
+			updates = append(updates, fmt.Sprintf("%s = excluded.%s", quoted[i], quoted[i]))
+		}
+	}
+
+	statement := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", d.quote(table), strings.Join(quoted, ", "), d.values(rows, len(columns)))
+	if d == DialectMySQL {
+		return statement + " ON DUPLICATE KEY UPDATE " + strings.Join(updates, ", ")
+	}
+
+	return fmt.Sprintf("%s ON CONFLICT (%s) DO UPDATE SET %s", statement, d.quote(key), strings.Join(updates, ", "))
+}
+
+// insert returns the statement inserting rows into table.
+func (d Dialect) insert(table string, columns []string, rows int) string {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = d.quote(column)
+	}
+
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", d.quote(table), strings.Join(quoted, ", "), d.values(rows, len(columns)))
+}
+
+// textType returns the type of a text column, MySQL requires a length for the key columns.
+func (d Dialect) textType(key bool) string {
+	if d == DialectMySQL && key {
+		return "VARCHAR(255)"
+	}
+
+	return "TEXT"
+}
+
+func (d Dialect) floatType() string {
+	switch d {
+	case DialectPostgres:
+		return "DOUBLE PRECISION"
+	case DialectMySQL:
+		return "DOUBLE"
+	default:
+		return "REAL"
+	}
+}
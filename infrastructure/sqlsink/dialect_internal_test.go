@@ -0,0 +1,38 @@
+package sqlsink
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDialect_upsert(t *testing.T) {
+	columns := []string{"id", "email", "name"}
+
+	tests := []struct {
+		dialect Dialect
+		want    string
+	}{
+		{
+			dialect: DialectPostgres,
+			want: `INSERT INTO "hr"."employees" ("id", "email", "name") VALUES ($1, $2, $3), ($4, $5, $6) ` +
+				`ON CONFLICT ("id") DO UPDATE SET "email" = excluded."email", "name" = excluded."name"`,
+		},
+		{
+			dialect: DialectSQLite,
+			want: `INSERT INTO "hr"."employees" ("id", "email", "name") VALUES (?, ?, ?), (?, ?, ?) ` +
+				`ON CONFLICT ("id") DO UPDATE SET "email" = excluded."email", "name" = excluded."name"`,
+		},
+		{
+			dialect: DialectMySQL,
+			want: "INSERT INTO `hr`.`employees` (`id`, `email`, `name`) VALUES (?, ?, ?), (?, ?, ?) " +
+				"ON DUPLICATE KEY UPDATE `email` = VALUES(`email`), `name` = VALUES(`name`)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.dialect), func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.dialect.upsert("hr.employees", "id", columns, 2))
+		})
+	}
+}
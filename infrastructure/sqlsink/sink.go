@@ -0,0 +1,237 @@
+package sqlsink
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vsantosalmeida/csv-parser/entity"
+	"github.com/vsantosalmeida/csv-parser/usecase/csv"
+)
+
+// defaultBatchSize is the rows by statement when Config.BatchSize is 0, kept under the 999 variables
+// limit of older SQLite versions.
+const defaultBatchSize = 100
+
+// Columns maps the fields of entity.Employee to the columns of the employees table.
+type Columns struct {
+	ID     string `json:"id"`
+	Email  string `json:"email"`
+	Name   string `json:"name"`
+	Salary string `json:"salary"`
+	Phone  string `json:"phone"`
+	// Attributes is the column with the custom attributes as a JSON object, they are not written when empty.
+	Attributes string `json:"attributes,omitempty"`
+}
+
+// BadDataColumns maps the fields of csv.BadData and its file to the columns of the bad data table,
+// the reasons are joined by "; ".
+type BadDataColumns struct {
+	File    string `json:"file"`
+	Line    string `json:"line"`
+	Reasons string `json:"reasons"`
+}
+
+// Config configures the Sink, the empty names use the defaults.
+type Config struct {
+	Dialect Dialect `json:"dialect"`
+	// Table is the employees table, "employees" by default, ID is its primary key.
+	Table   string  `json:"table,omitempty"`
+	Columns Columns `json:"columns,omitempty"`
+	// BadDataTable is the companion table with the bad data, "bad_data" by default.
+	BadDataTable   string         `json:"bad_data_table,omitempty"`
+	BadDataColumns BadDataColumns `json:"bad_data_columns,omitempty"`
+	// BatchSize is the max rows inserted by statement, 100 by default.
+	BatchSize int `json:"batch_size,omitempty"`
+}
+
+// Sink is a csv.Sink upserting the employees of each file into a SQL database by ID, with the bad data of
+// the file in a companion table. Each file is written in a transaction.
+type Sink struct {
+	db     *sql.DB
+	config Config
+}
+
+// NewSink validates the Config and returns a Sink writing to db, the driver of the db must match the Dialect.
+func NewSink(db *sql.DB, config Config) (*Sink, error) {
+	if err := validateDialect(config.Dialect); err != nil {
+		return nil, err
+	}
+
+	setDefault(&config.Table, "employees")
+	setDefault(&config.Columns.ID, "id")
+	setDefault(&config.Columns.Email, "email")
+	setDefault(&config.Columns.Name, "name")
+	setDefault(&config.Columns.Salary, "salary")
+	setDefault(&config.Columns.Phone, "phone")
+	setDefault(&config.BadDataTable, "bad_data")
+	setDefault(&config.BadDataColumns.File, "file")
+	setDefault(&config.BadDataColumns.Line, "line")
+	setDefault(&config.BadDataColumns.Reasons, "reasons")
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultBatchSize
+	}
+
+	names := append([]string{config.Table, config.BadDataTable}, config.employeeColumns()...)
+	for _, name := range append(names, config.badDataColumns()...) {
+		if !identifier.MatchString(name) {
+			return nil, fmt.Errorf("invalid table or column name %q", name)
+		}
+	}
+
+	return &Sink{db: db, config: config}, nil
+}
+
+// CreateTables creates the employees and bad data tables when they do not exist.
+func (s *Sink) CreateTables(ctx context.Context) error {
+	d, c := s.config.Dialect, s.config
+	var attributes string
+	if c.Columns.Attributes != "" {
+		attributes = fmt.Sprintf(", %s %s", d.quote(c.Columns.Attributes), d.textType(false))
+	}
+	statements := []string{
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s %s PRIMARY KEY, %s %s NOT NULL, %s %s NOT NULL, %s %s NOT NULL, %s %s%s)",
+			d.quote(c.Table),
+			d.quote(c.Columns.ID), d.textType(true),
+			d.quote(c.Columns.Email), d.textType(false),
+			d.quote(c.Columns.Name), d.textType(false),
+			d.quote(c.Columns.Salary), d.floatType(),
+			d.quote(c.Columns.Phone), d.textType(false),
+			attributes),
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s %s NOT NULL, %s %s NOT NULL, %s %s NOT NULL)",
+			d.quote(c.BadDataTable),
+			d.quote(c.BadDataColumns.File), d.textType(true),
+			d.quote(c.BadDataColumns.Line), d.textType(false),
+			d.quote(c.BadDataColumns.Reasons), d.textType(false)),
+	}
+
+	for _, statement := range statements {
+		if _, err := s.db.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteFile upserts the employees and replaces the bad data of the file in a transaction, so running a file
+// again does not duplicate its bad data.
+func (s *Sink) WriteFile(file string, employees []*entity.Employee, badData []*csv.BadData) (err error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = s.upsertEmployees(tx, employees); err != nil {
+		return err
+	}
+
+	if err = s.replaceBadData(tx, file, badData); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"event":     "sql_sink_file_written",
+		"file":      file,
+		"employees": len(employees),
+		"bad_lines": len(badData),
+	}).Info()
+
+	return nil
+}
+
+func (s *Sink) upsertEmployees(tx *sql.Tx, employees []*entity.Employee) error {
+	columns := s.config.employeeColumns()
+	for start := 0; start < len(employees); start += s.config.BatchSize {
+		batch := employees[start:min(start+s.config.BatchSize, len(employees))]
+
+		args := make([]interface{}, 0, len(batch)*len(columns))
+		for _, e := range batch {
+			args = append(args, e.ID, e.Email, e.Name, e.Salary, sql.NullString{String: e.Phone, Valid: e.Phone != ""})
+			if s.config.Columns.Attributes == "" {
+				continue
+			}
+
+			var attributes sql.NullString
+			if len(e.Attributes) != 0 {
+				b, err := json.Marshal(e.Attributes)
+				if err != nil {
+					return fmt.Errorf("could not encode the attributes of the employee %s: %w", e.ID, err)
+				}
+				attributes = sql.NullString{String: string(b), Valid: true}
+			}
+			args = append(args, attributes)
+		}
+
+		statement := s.config.Dialect.upsert(s.config.Table, s.config.Columns.ID, columns, len(batch))
+		if _, err := tx.Exec(statement, args...); err != nil {
+			return fmt.Errorf("could not upsert the employees: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Sink) replaceBadData(tx *sql.Tx, file string, badData []*csv.BadData) error {
+	d := s.config.Dialect
+	statement := fmt.Sprintf("DELETE FROM %s WHERE %s = %s", d.quote(s.config.BadDataTable), d.quote(s.config.BadDataColumns.File), d.placeholder(1))
+	if _, err := tx.Exec(statement, file); err != nil {
+		return fmt.Errorf("could not delete the previous bad data: %w", err)
+	}
+
+	columns := s.config.badDataColumns()
+	for start := 0; start < len(badData); start += s.config.BatchSize {
+		batch := badData[start:min(start+s.config.BatchSize, len(badData))]
+
+		args := make([]interface{}, 0, len(batch)*len(columns))
+		for _, line := range batch {
+			args = append(args, file, line.Line.String(), strings.Join(line.Reasons, "; "))
+		}
+
+		if _, err := tx.Exec(d.insert(s.config.BadDataTable, columns, len(batch)), args...); err != nil {
+			return fmt.Errorf("could not insert the bad data: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// employeeColumns returns the employee columns in the order of the upsert args.
+func (c Config) employeeColumns() []string {
+	columns := []string{c.Columns.ID, c.Columns.Email, c.Columns.Name, c.Columns.Salary, c.Columns.Phone}
+	if c.Columns.Attributes != "" {
+		columns = append(columns, c.Columns.Attributes)
+	}
+
+	return columns
+}
+
+// badDataColumns returns the bad data columns in the order of the insert args.
+func (c Config) badDataColumns() []string {
+	return []string{c.BadDataColumns.File, c.BadDataColumns.Line, c.BadDataColumns.Reasons}
+}
+
+func setDefault(value *string, def string) {
+	if *value == "" {
+		*value = def
+	}
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
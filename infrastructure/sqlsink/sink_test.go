@@ -0,0 +1,216 @@
+package sqlsink_test
+
+import (
+	"context"
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/vsantosalmeida/csv-parser/entity"
+	"github.com/vsantosalmeida/csv-parser/infrastructure/sqlsink"
+	"github.com/vsantosalmeida/csv-parser/usecase/csv"
+)
+
+func init() {
+	log.SetOutput(ioutil.Discard)
+}
+
+func newDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// each connection to ":memory:" is a new database.
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+type row struct {
+	ID     string
+	Email  string
+	Name   string
+	Salary float64
+	Phone  sql.NullString
+}
+
+func TestSink_WriteFile(t *testing.T) {
+	db := newDB(t)
+	sink, err := sqlsink.NewSink(db, sqlsink.Config{
+		Dialect:      sqlsink.DialectSQLite,
+		Table:        "staff",
+		Columns:      sqlsink.Columns{ID: "employee_id", Salary: "wage"},
+		BadDataTable: "staff_rejects",
+		BatchSize:    2,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, sink.CreateTables(context.Background()))
+
+	givenEmployees := []*entity.Employee{
+		{ID: "1", Email: "doe@test.com", Name: "John Doe", Salary: 10},
+		{ID: "2", Email: "mary@test.com", Name: "Mary Jane", Salary: 15, Phone: "555"},
+		{ID: "3", Email: "max@test.com", Name: "Max Topperson", Salary: 11},
+	}
+	givenBadData := []*csv.BadData{
+		{Line: "5", Reasons: []string{"an id is required", "a name is required"}},
+	}
+	assert.NoError(t, sink.WriteFile("roster1.csv", givenEmployees, givenBadData))
+
+	// a second run updates the employees by ID and replaces the bad data of the file.
+	givenEmployees = []*entity.Employee{
+		{ID: "2", Email: "mary.jane@test.com", Name: "Mary Jane", Salary: 16},
+	}
+	givenBadData = []*csv.BadData{
+		{Line: "6", Reasons: []string{"an id is required"}},
+	}
+	assert.NoError(t, sink.WriteFile("roster1.csv", givenEmployees, givenBadData))
+
+	rows, err := db.Query(`SELECT employee_id, email, name, wage, phone FROM staff ORDER BY employee_id`)
+	assert.NoError(t, err)
+	var got []row
+	for rows.Next() {
+		var r row
+		assert.NoError(t, rows.Scan(&r.ID, &r.Email, &r.Name, &r.Salary, &r.Phone))
+		got = append(got, r)
+	}
+	assert.NoError(t, rows.Close())
+	assert.Equal(t, []row{
+		{ID: "1", Email: "doe@test.com", Name: "John Doe", Salary: 10},
+		{ID: "2", Email: "mary.jane@test.com", Name: "Mary Jane", Salary: 16},
+		{ID: "3", Email: "max@test.com", Name: "Max Topperson", Salary: 11},
+	}, got)
+
+	var file, line, reasons string
+	assert.NoError(t, db.QueryRow(`SELECT count(*) FROM staff_rejects`).Scan(&line))
+	assert.Equal(t, "1", line)
+	assert.NoError(t, db.QueryRow(`SELECT file, line, reasons FROM staff_rejects`).Scan(&file, &line, &reasons))
+	assert.Equal(t, "roster1.csv", file)
+	assert.Equal(t, "6", line)
+	assert.Equal(t, "an id is required", reasons)
+}
+
+func TestSink_WriteFile_Attributes(t *testing.T) {
+	db := newDB(t)
+	sink, err := sqlsink.NewSink(db, sqlsink.Config{Dialect: sqlsink.DialectSQLite, Columns: sqlsink.Columns{Attributes: "extra"}})
+	assert.NoError(t, err)
+	assert.NoError(t, sink.CreateTables(context.Background()))
+
+	givenEmployees := []*entity.Employee{
+		{ID: "1", Email: "doe@test.com", Name: "John Doe", Salary: 10, Attributes: entity.Attributes{"department": "Sales", "level": float64(3)}},
+		{ID: "2", Email: "mary@test.com", Name: "Mary Jane", Salary: 15},
+	}
+	assert.NoError(t, sink.WriteFile("roster1.csv", givenEmployees, nil))
+
+	var got []sql.NullString
+	rows, err := db.Query(`SELECT extra FROM employees ORDER BY id`)
+	assert.NoError(t, err)
+	for rows.Next() {
+		var extra sql.NullString
+		assert.NoError(t, rows.Scan(&extra))
+		got = append(got, extra)
+	}
+	assert.NoError(t, rows.Close())
+	assert.Equal(t, []sql.NullString{{String: `{"department":"Sales","level":3}`, Valid: true}, {}}, got)
+}
+
+func TestSink_WriteFile_Rollback(t *testing.T) {
+	db := newDB(t)
+	sink, err := sqlsink.NewSink(db, sqlsink.Config{Dialect: sqlsink.DialectSQLite})
+	assert.NoError(t, err)
+	assert.NoError(t, sink.CreateTables(context.Background()))
+	_, err = db.Exec(`DROP TABLE bad_data`)
+	assert.NoError(t, err)
+
+	givenEmployees := []*entity.Employee{{ID: "1", Email: "doe@test.com", Name: "John Doe", Salary: 10}}
+	assert.Error(t, sink.WriteFile("roster1.csv", givenEmployees, nil))
+
+	var count int
+	assert.NoError(t, db.QueryRow(`SELECT count(*) FROM employees`).Scan(&count))
+	assert.Zero(t, count)
+}
+
+func TestNewSink_Error(t *testing.T) {
+	db := newDB(t)
+
+	_, err := sqlsink.NewSink(db, sqlsink.Config{Dialect: "oracle"})
+	assert.Error(t, err)
+
+	_, err = sqlsink.NewSink(db, sqlsink.Config{Dialect: sqlsink.DialectPostgres, Table: "employees; DROP TABLE x"})
+	assert.Error(t, err)
+}
+
+func TestSink_ParseFiles(t *testing.T) {
+	db := newDB(t)
+	sink, err := sqlsink.NewSink(db, sqlsink.Config{Dialect: sqlsink.DialectSQLite})
+	assert.NoError(t, err)
+	assert.NoError(t, sink.CreateTables(context.Background()))
+
+	dir, err := ioutil.TempDir("", "sqlsink")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	parser, err := csv.NewParser(map[string]*csv.FilePattern{
+		"../../usecase/csv/test_files/roster1.csv": {
+			FirstNameColumn: "Name",
+			SalaryColumn:    "Wage",
+			EmailColumn:     "Email",
+			IDColumn:        "Number",
+		},
+	}, csv.WithSink(sink), csv.WithOutputDir(dir))
+	assert.NoError(t, err)
+	assert.Empty(t, parser.ParseFiles([]string{"../../usecase/csv/test_files/roster1.csv"}))
+
+	var employees, badLines int
+	assert.NoError(t, db.QueryRow(`SELECT count(*) FROM employees`).Scan(&employees))
+	assert.NoError(t, db.QueryRow(`SELECT count(*) FROM bad_data`).Scan(&badLines))
+	assert.Equal(t, 3, employees)
+	assert.Equal(t, 2, badLines)
+}
+
+func TestSink_ParseFiles_FixedFile(t *testing.T) {
+	db := newDB(t)
+	sink, err := sqlsink.NewSink(db, sqlsink.Config{Dialect: sqlsink.DialectSQLite})
+	assert.NoError(t, err)
+	assert.NoError(t, sink.CreateTables(context.Background()))
+
+	dir, err := ioutil.TempDir("", "sqlsink")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	givenFile := filepath.Join(dir, "roster.csv")
+	parser, err := csv.NewParser(map[string]*csv.FilePattern{
+		givenFile: {
+			FirstNameColumn: "Name",
+			SalaryColumn:    "Wage",
+			EmailColumn:     "Email",
+			IDColumn:        "Number",
+		},
+	}, csv.WithSink(sink), csv.WithOutputDir(dir))
+	assert.NoError(t, err)
+
+	var badLines int
+	if err := ioutil.WriteFile(givenFile, []byte("Name,Email,Wage,Number\nJohn Doe,doe@test.com,$10,1\nMary Jane,marytest.com,$15,2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, parser.ParseFiles([]string{givenFile}))
+	assert.NoError(t, db.QueryRow(`SELECT count(*) FROM bad_data`).Scan(&badLines))
+	assert.Equal(t, 1, badLines)
+
+	// the fixed file has no employees nor bad data, its previous bad data must still be deleted.
+	if err := ioutil.WriteFile(givenFile, []byte("Name,Email,Wage,Number\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, parser.ParseFiles([]string{givenFile}))
+	assert.NoError(t, db.QueryRow(`SELECT count(*) FROM bad_data`).Scan(&badLines))
+	assert.Equal(t, 0, badLines)
+
+	var employees int
+	assert.NoError(t, db.QueryRow(`SELECT count(*) FROM employees`).Scan(&employees))
+	assert.Equal(t, 1, employees)
+}
@@ -0,0 +1,227 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vsantosalmeida/csv-parser/entity"
+	"github.com/vsantosalmeida/csv-parser/usecase/csv"
+)
+
+// Events notified by the Notifier, sent in the HeaderEvent and in the payload.
+const (
+	EventRunFinished   = "run_finished"
+	EventFileProcessed = "file_processed"
+)
+
+// Headers of each notification.
+const (
+	HeaderEvent = "X-Webhook-Event"
+	// HeaderDelivery is the ID of the notification, the same in all its attempts.
+	HeaderDelivery = "X-Webhook-Delivery"
+	// HeaderSignature is the "sha256=<hex>" HMAC of the body with the secret, only set with a secret.
+	HeaderSignature = "X-Webhook-Signature-256"
+)
+
+const (
+	defaultMaxAttempts = 3
+	defaultBackoff     = time.Second
+	defaultTimeout     = 10 * time.Second
+)
+
+// Config configures the Notifier, the empty values use the defaults.
+type Config struct {
+	URL string
+	// Secret signs the body of each notification in the HeaderSignature, not signed when empty.
+	Secret string
+	// PerFile also notifies each processed file with employees or bad data, before the run notification.
+	PerFile bool
+	// MaxAttempts is the max requests of a notification, 3 by default.
+	MaxAttempts int
+	// Backoff is the wait before the second attempt, doubled after each failure, 1s by default.
+	Backoff time.Duration
+	// Timeout is the timeout of each request, 10s by default.
+	Timeout time.Duration
+}
+
+// RunPayload is the body of the EventRunFinished notification.
+type RunPayload struct {
+	Event     string            `json:"event"`
+	Files     int               `json:"files"`
+	Employees int               `json:"employees"`
+	BadLines  int               `json:"bad_lines"`
+	ErrorKeys []string          `json:"error_keys"`
+	Errors    map[string]string `json:"errors,omitempty"`
+	// EmployeesFile and BadDataFile are the paths of the result files, empty when not written.
+	EmployeesFile string `json:"employees_file,omitempty"`
+	BadDataFile   string `json:"bad_data_file,omitempty"`
+}
+
+// FilePayload is the body of the EventFileProcessed notification.
+type FilePayload struct {
+	Event     string `json:"event"`
+	File      string `json:"file"`
+	Employees int    `json:"employees"`
+	BadLines  int    `json:"bad_lines"`
+}
+
+// Notifier is a csv.SummarySink posting a JSON notification to the URL when a run finishes, and optionally
+// after each processed file.
+//
+// A notification is sent again on a network error, a 5xx or a 429 status, up to the Config.MaxAttempts.
+type Notifier struct {
+	config Config
+	client *http.Client
+	sleep  func(time.Duration)
+}
+
+// NewNotifier validates the Config and returns a Notifier posting to the URL.
+func NewNotifier(config Config) (*Notifier, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("the webhook URL is required")
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaultMaxAttempts
+	}
+	if config.Backoff <= 0 {
+		config.Backoff = defaultBackoff
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultTimeout
+	}
+
+	return &Notifier{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+		sleep:  time.Sleep,
+	}, nil
+}
+
+// WriteFile notifies the file when Config.PerFile is set.
+func (n *Notifier) WriteFile(file string, employees []*entity.Employee, badData []*csv.BadData) error {
+	if !n.config.PerFile {
+		return nil
+	}
+
+	return n.notify(EventFileProcessed, FilePayload{
+		Event:     EventFileProcessed,
+		File:      file,
+		Employees: len(employees),
+		BadLines:  len(badData),
+	})
+}
+
+// WriteSummary notifies the end of the run with the Summary.
+func (n *Notifier) WriteSummary(summary *csv.Summary) error {
+	keys := make([]string, 0, len(summary.Errors))
+	for k := range summary.Errors {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return n.notify(EventRunFinished, RunPayload{
+		Event:         EventRunFinished,
+		Files:         summary.Files,
+		Employees:     summary.Employees,
+		BadLines:      summary.BadLines,
+		ErrorKeys:     keys,
+		Errors:        summary.Errors,
+		EmployeesFile: summary.EmployeesFile,
+		BadDataFile:   summary.BadDataFile,
+	})
+}
+
+// Sign returns the HeaderSignature value of the body, for the receivers to compare with hmac.Equal.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (n *Notifier) notify(event string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	delivery, err := newDeliveryID()
+	if err != nil {
+		return err
+	}
+
+	backoff := n.config.Backoff
+	for attempt := 1; ; attempt++ {
+		retry, err := n.post(event, delivery, body)
+		if err == nil {
+			log.WithFields(log.Fields{
+				"event":         "webhook_notified",
+				"webhook_event": event,
+				"delivery":      delivery,
+				"attempt":       attempt,
+			}).Info()
+			return nil
+		}
+		if !retry || attempt == n.config.MaxAttempts {
+			return fmt.Errorf("could not notify the webhook after %d attempts: %w", attempt, err)
+		}
+
+		log.WithFields(log.Fields{
+			"event":    "webhook_notify_failed",
+			"delivery": delivery,
+			"attempt":  attempt,
+			"reason":   err,
+		}).Warn("could not notify the webhook, retrying")
+		n.sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// post sends the notification, returning if the failure must be retried.
+func (n *Notifier) post(event, delivery string, body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, n.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, event)
+	req.Header.Set(HeaderDelivery, delivery)
+	if n.config.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(n.config.Secret, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	// the body is drained so the connection can be reused.
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("the webhook returned the status %d", resp.StatusCode)
+}
+
+func newDeliveryID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,150 @@
+package webhook_test
+
+import (
+	"crypto/hmac"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/vsantosalmeida/csv-parser/entity"
+	"github.com/vsantosalmeida/csv-parser/infrastructure/webhook"
+	"github.com/vsantosalmeida/csv-parser/usecase/csv"
+)
+
+const givenSecret = "s3cr3t"
+
+func init() {
+	log.SetOutput(ioutil.Discard)
+}
+
+type request struct {
+	event     string
+	delivery  string
+	signature string
+	body      []byte
+}
+
+// receiver is a webhook answering with the statuses in order, then 200.
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []request
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := ioutil.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, request{
+		event:     req.Header.Get(webhook.HeaderEvent),
+		delivery:  req.Header.Get(webhook.HeaderDelivery),
+		signature: req.Header.Get(webhook.HeaderSignature),
+		body:      body,
+	})
+
+	status := http.StatusOK
+	if len(r.statuses) != 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func newReceiver(t *testing.T, statuses ...int) (*receiver, *httptest.Server) {
+	r := &receiver{statuses: statuses}
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+
+	return r, server
+}
+
+func TestNotifier_ParseFiles(t *testing.T) {
+	r, server := newReceiver(t, http.StatusServiceUnavailable)
+	notifier, err := webhook.NewNotifier(webhook.Config{
+		URL:     server.URL,
+		Secret:  givenSecret,
+		PerFile: true,
+		Backoff: time.Millisecond,
+	})
+	assert.NoError(t, err)
+
+	dir, err := ioutil.TempDir("", "webhook")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	givenFile := "../../usecase/csv/test_files/roster1.csv"
+	parser, err := csv.NewParser(map[string]*csv.FilePattern{
+		givenFile: {
+			FirstNameColumn: "Name",
+			SalaryColumn:    "Wage",
+			EmailColumn:     "Email",
+			IDColumn:        "Number",
+		},
+	}, csv.WithSink(notifier), csv.WithOutputDir(dir))
+	assert.NoError(t, err)
+	errs := parser.ParseFiles([]string{givenFile, "not_found.csv"})
+	assert.Len(t, errs, 1)
+
+	// the first file notification is retried after the 503.
+	if !assert.Len(t, r.requests, 3) {
+		return
+	}
+	assert.Equal(t, r.requests[0].delivery, r.requests[1].delivery)
+	for _, req := range r.requests {
+		assert.True(t, hmac.Equal([]byte(webhook.Sign(givenSecret, req.body)), []byte(req.signature)))
+	}
+
+	var file webhook.FilePayload
+	assert.Equal(t, webhook.EventFileProcessed, r.requests[1].event)
+	assert.NoError(t, json.Unmarshal(r.requests[1].body, &file))
+	assert.Equal(t, webhook.FilePayload{Event: webhook.EventFileProcessed, File: givenFile, Employees: 3, BadLines: 2}, file)
+
+	var run webhook.RunPayload
+	assert.Equal(t, webhook.EventRunFinished, r.requests[2].event)
+	assert.NoError(t, json.Unmarshal(r.requests[2].body, &run))
+	assert.Equal(t, 2, run.Files)
+	assert.Equal(t, 3, run.Employees)
+	assert.Equal(t, 2, run.BadLines)
+	assert.Equal(t, []string{"not_found.csv"}, run.ErrorKeys)
+	assert.Equal(t, parser.Summary().EmployeesFile, run.EmployeesFile)
+	assert.Equal(t, parser.Summary().BadDataFile, run.BadDataFile)
+}
+
+func TestNotifier_WriteSummary_Error(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		requests int
+	}{
+		{name: "retries until the max attempts", statuses: []int{500, 502, 429}, requests: 3},
+		{name: "client error is not retried", statuses: []int{400}, requests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, server := newReceiver(t, tt.statuses...)
+			notifier, err := webhook.NewNotifier(webhook.Config{URL: server.URL, Backoff: time.Millisecond})
+			assert.NoError(t, err)
+
+			assert.Error(t, notifier.WriteSummary(&csv.Summary{Files: 1}))
+			assert.Len(t, r.requests, tt.requests)
+			assert.Empty(t, r.requests[0].signature)
+		})
+	}
+}
+
+func TestNotifier_WriteFile_NotPerFile(t *testing.T) {
+	r, server := newReceiver(t)
+	notifier, err := webhook.NewNotifier(webhook.Config{URL: server.URL})
+	assert.NoError(t, err)
+
+	assert.NoError(t, notifier.WriteFile("roster1.csv", []*entity.Employee{{ID: "1"}}, nil))
+	assert.Empty(t, r.requests)
+}
@@ -15,6 +15,53 @@ const (
 	ErrReadingFile                 = err("could not read the given file")
 	ErrUnprocessableFile           = err("could not find a file pattern to process")
 	ErrWriteFile                   = err("could not write the result file")
+	ErrInvalidPolicy               = err("the given policy is invalid")
+	ErrFailFast                    = err("the run was stopped on the first invalid line")
+	ErrBadDataThreshold            = err("the file exceeded the bad data threshold")
+	ErrRunAborted                  = err("the file was not processed because the run was aborted")
+	ErrAllOrNothing                = err("the employees were not written because a file finished with errors")
+	ErrInvalidFilePatternConfig    = err("could not decode the file patterns config")
+	ErrUnsupportedFormat           = err("the format is not supported")
+	ErrInvalidDialect              = err("the CSV dialect is invalid")
+	ErrInvalidEncoding             = err("the encoding is invalid or the bytes could not be decoded")
+	ErrMalformedLine               = err("the line could not be parsed")
+	ErrInvalidSheet                = err("the XLSX sheet config is invalid")
+	ErrSheetNotFound               = err("the sheet was not found in the workbook")
+	ErrInvalidFixedWidth           = err("the fixed-width columns are invalid")
+	ErrShortLine                   = err("the line is shorter than the fixed-width columns")
+	ErrInvalidGlob                 = err("the file pattern name is not a valid glob")
+	ErrRunCancelled                = err("the run was cancelled")
+	ErrInvalidJob                  = err("the job request is invalid")
+	ErrJobNotFound                 = err("the job was not found")
+	ErrJobFinished                 = err("the job has already finished")
+	ErrJobInterrupted              = err("the job was interrupted before finishing")
+	ErrWriteSink                   = err("could not write the results to the sink")
+	ErrInvalidProvenance           = err("the provenance mode must be embedded or sidecar")
+	ErrInvalidAttributePattern     = err("the custom attribute config is invalid")
+	ErrInvalidAttribute            = err("the custom attribute value is invalid")
+	ErrInvalidMerge                = err("the merge precedence must be file_order, non_empty or most_recent")
+)
+
+// Operations reported by a FileError.
+const (
+	OpOpen        = "open"
+	OpRead        = "read"
+	OpFindPattern = "find pattern"
+	OpParse       = "parse"
+	OpWrite       = "write"
+)
+
+// Fields reported by a ValidationError.
+const (
+	FieldID     = "id"
+	FieldEmail  = "email"
+	FieldName   = "name"
+	FieldSalary = "salary"
+	// FieldAttributes prefixes the Field of a custom attribute, like "attributes.department".
+	FieldAttributes = "attributes"
+	// FieldRecord is used by failures on the whole line, like undecodable bytes in any of its columns
+	// or a malformed line.
+	FieldRecord = "record"
 )
 
 type err string
@@ -28,3 +75,41 @@ func (e err) Error() string {
 func NewError(err err, cause string) error {
 	return fmt.Errorf("%w: %s", err, cause)
 }
+
+// ValidationError is returned when a value from a line could not be used to build an entity.Employee field.
+//
+// Code holds one of the error constants, so errors.Is(err, ErrInvalidSalaryValue) can be used to find
+// the kind of the failure, while Field, Column, Line and Value give its position.
+type ValidationError struct {
+	Field string
+	// Column is the name of the file column mapped to the Field.
+	Column string
+	Line   int
+	Value  string
+	Code   error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("line %d: %s %q: %s", e.Line, e.Field, e.Value, e.Code)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Code
+}
+
+// FileError is returned when an operation over a whole file fails, like opening or reading it.
+//
+// Err holds the cause, usually one of the error constants wrapped by NewError.
+type FileError struct {
+	Path string
+	Op   string
+	Err  error
+}
+
+func (e *FileError) Error() string {
+	return fmt.Sprintf("%s %s: %s", e.Op, e.Path, e.Err)
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
@@ -86,3 +86,35 @@ func TestNewError(t *testing.T) {
 	assert.ErrorIs(t, e, ErrEmptyName)
 	assert.Equal(t, e.Error(), want)
 }
+
+func TestValidationError(t *testing.T) {
+	var e error = &ValidationError{
+		Field: FieldSalary,
+		Line:  3,
+		Value: "$0",
+		Code:  ErrInvalidSalaryValue,
+	}
+	want := `line 3: salary "$0": could not convert salary to a float value or salary is less or equals to 0`
+
+	var target *ValidationError
+	assert.ErrorIs(t, e, ErrInvalidSalaryValue)
+	assert.ErrorAs(t, e, &target)
+	assert.Equal(t, FieldSalary, target.Field)
+	assert.Equal(t, 3, target.Line)
+	assert.Equal(t, want, e.Error())
+}
+
+func TestFileError(t *testing.T) {
+	var e error = &FileError{
+		Path: "roster.csv",
+		Op:   OpOpen,
+		Err:  NewError(ErrOpeningFile, "no such file or directory"),
+	}
+	want := "open roster.csv: could not open the given file: no such file or directory"
+
+	var target *FileError
+	assert.ErrorIs(t, e, ErrOpeningFile)
+	assert.ErrorAs(t, e, &target)
+	assert.Equal(t, "roster.csv", target.Path)
+	assert.Equal(t, want, e.Error())
+}
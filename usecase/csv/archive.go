@@ -0,0 +1,210 @@
+package csv
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	errs "github.com/vsantosalmeida/csv-parser/pkg/errors"
+)
+
+// MemberSeparator separates the archive path from the member path in the key of an archive member,
+// like "archive.zip!roster1.csv".
+const MemberSeparator = "!"
+
+// compressions are the extensions of the compressed files, decompressed before reading the file.
+var compressions = map[string]func(r io.Reader) (io.ReadCloser, error){
+	".gz": func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	},
+	".bz2": func(r io.Reader) (io.ReadCloser, error) {
+		return io.NopCloser(bzip2.NewReader(r)), nil
+	},
+	".zst": func(r io.Reader) (io.ReadCloser, error) {
+		decoder, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	},
+}
+
+// tarExtensions are the short extensions of the compressed tar archives, like "roster.tgz".
+var tarExtensions = map[string]string{
+	".tgz":  ".tar.gz",
+	".tbz2": ".tar.bz2",
+	".tzst": ".tar.zst",
+}
+
+// inputFile is a file to be parsed, one of the files given to the Parser or a member of an archive.
+type inputFile struct {
+	// key identifies the file in the results, the file path or "archive.zip!member.csv" for an archive member.
+	key string
+	// member is the path of the file inside the archive, empty when the file is not an archive member.
+	member string
+	// name is the file or member path without the compression extension, used to find its Format.
+	name   string
+	reader io.Reader
+	// modTime is the modification time of the file or archive member, used by MergeMostRecent.
+	modTime time.Time
+}
+
+// walkFile opens the file and calls fn with its content, decompressed when the file has a compression extension.
+// The zip and tar archives are expanded and fn is called with each member, in the archive order.
+//
+// The returned error is a *errs.FileError for the whole file, the errors of a member are handled by fn.
+func walkFile(file string, fn func(input *inputFile)) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return &errs.FileError{Path: file, Op: errs.OpOpen, Err: errs.NewError(errs.ErrOpeningFile, err.Error())}
+	}
+	defer f.Close()
+
+	name := file
+	if ext, ok := tarExtensions[strings.ToLower(path.Ext(name))]; ok {
+		name = strings.TrimSuffix(name, path.Ext(name)) + ext
+	}
+
+	if strings.EqualFold(path.Ext(name), ".zip") {
+		if err := walkZip(file, f, fn); err != nil {
+			return &errs.FileError{Path: file, Op: errs.OpRead, Err: errs.NewError(errs.ErrReadingFile, err.Error())}
+		}
+		return nil
+	}
+
+	reader, name, closeReader, err := decompress(f, name)
+	if err != nil {
+		return &errs.FileError{Path: file, Op: errs.OpRead, Err: errs.NewError(errs.ErrReadingFile, err.Error())}
+	}
+	defer closeReader()
+
+	if strings.EqualFold(path.Ext(name), ".tar") {
+		if err := walkTar(file, reader, fn); err != nil {
+			return &errs.FileError{Path: file, Op: errs.OpRead, Err: errs.NewError(errs.ErrReadingFile, err.Error())}
+		}
+		return nil
+	}
+
+	var modTime time.Time
+	if info, err := f.Stat(); err == nil {
+		modTime = info.ModTime()
+	}
+
+	fn(&inputFile{key: file, name: name, reader: reader, modTime: modTime})
+	return nil
+}
+
+func walkZip(file string, f *os.File, fn func(input *inputFile)) error {
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	archive, err := zip.NewReader(f, info.Size())
+	if err != nil {
+		return err
+	}
+
+	for _, member := range archive.File {
+		if member.FileInfo().IsDir() || ignoredMember(member.Name) {
+			continue
+		}
+
+		rc, err := member.Open()
+		if err != nil {
+			return err
+		}
+		walkMember(file, member.Name, member.Modified, rc, fn)
+		rc.Close()
+	}
+
+	return nil
+}
+
+func walkTar(file string, r io.Reader, fn func(input *inputFile)) error {
+	archive := tar.NewReader(r)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if header.Typeflag != tar.TypeReg || ignoredMember(header.Name) {
+			continue
+		}
+		walkMember(file, header.Name, header.ModTime, archive, fn)
+	}
+}
+
+// walkMember calls fn with the decompressed member, a member that can not be decompressed is given
+// with a reader returning the error, so it is reported with the member key.
+func walkMember(file, member string, modTime time.Time, r io.Reader, fn func(input *inputFile)) {
+	input := &inputFile{
+		key:     file + MemberSeparator + member,
+		member:  member,
+		modTime: modTime,
+	}
+
+	reader, name, closeReader, err := decompress(r, member)
+	if err != nil {
+		input.name, input.reader = member, &errReader{err: err}
+		fn(input)
+		return
+	}
+	defer closeReader()
+
+	if isArchive(name) {
+		input.name, input.reader = name, &errReader{err: errs.NewError(errs.ErrUnsupportedFormat, "the archives inside an archive are not expanded")}
+		fn(input)
+		return
+	}
+
+	input.name, input.reader = name, reader
+	fn(input)
+}
+
+func isArchive(name string) bool {
+	ext := strings.ToLower(path.Ext(name))
+	_, tarExt := tarExtensions[ext]
+	return ext == ".zip" || ext == ".tar" || tarExt
+}
+
+// ignoredMember reports if an archive member is metadata added by the archivers, like "__MACOSX/._roster.csv".
+func ignoredMember(name string) bool {
+	return strings.HasPrefix(name, "__MACOSX/") || strings.HasPrefix(path.Base(name), "._")
+}
+
+// decompress returns the decompressed content of a file with a compression extension, and the file name
+// without the extension, "roster.csv.gz" is "roster.csv". The other files are returned as they are.
+func decompress(r io.Reader, name string) (io.Reader, string, func(), error) {
+	ext := strings.ToLower(path.Ext(name))
+	newReader, ok := compressions[ext]
+	if !ok {
+		return r, name, func() {}, nil
+	}
+
+	rc, err := newReader(r)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return rc, name[:len(name)-len(ext)], func() { rc.Close() }, nil
+}
+
+type errReader struct {
+	err error
+}
+
+func (r *errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
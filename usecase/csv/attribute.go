@@ -0,0 +1,211 @@
+package csv
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vsantosalmeida/csv-parser/entity"
+	errs "github.com/vsantosalmeida/csv-parser/pkg/errors"
+)
+
+// AttributeType is the type of the value of a custom attribute.
+type AttributeType string
+
+const (
+	AttributeString AttributeType = "string"
+	AttributeNumber AttributeType = "number"
+	// AttributeDate values are read with the AttributePattern.Layout and written as "2006-01-02".
+	AttributeDate AttributeType = "date"
+	// AttributeBool values are "true", "false", "yes", "no" or the strconv.ParseBool values, in any case.
+	AttributeBool AttributeType = "bool"
+)
+
+const attributeDateLayout = "2006-01-02"
+
+// reservedAttributes are the names of the entity.Employee fields, not allowed as attribute names since
+// the attributes are columns of the CSV result files.
+var reservedAttributes = map[string]bool{"id": true, "email": true, "name": true, "salary": true, "phone": true}
+
+// AttributePattern maps a column of the file to a custom attribute of the entity.Employee, like
+// {"name": "department", "column": "Dept", "values": ["Sales", "IT"]}.
+//
+// A value breaking one of the rules of its Type makes the line a bad data.
+type AttributePattern struct {
+	Name   string `json:"name"`
+	Column string `json:"column"`
+	// Type of the value, AttributeString when empty.
+	Type AttributeType `json:"type,omitempty"`
+	// Required makes an empty value invalid, the attribute is omitted from the employee otherwise.
+	Required bool `json:"required,omitempty"`
+	// Pattern is a regular expression an AttributeString value must match.
+	Pattern string `json:"pattern,omitempty"`
+	// Values holds the allowed AttributeString values, any value when empty.
+	Values []string `json:"values,omitempty"`
+	// Min and Max limit an AttributeNumber value, no limit when nil.
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+	// Layout is the time.Parse layout of an AttributeDate value, "2006-01-02" when empty.
+	Layout string `json:"layout,omitempty"`
+
+	// re is the compiled Pattern, set by validateAttributes.
+	re *regexp.Regexp
+}
+
+func validateAttributes(attributes []*AttributePattern) error {
+	names := make(map[string]bool, len(attributes))
+	for _, a := range attributes {
+		invalid := func(cause string) error {
+			return errs.NewError(errs.ErrInvalidAttributePattern, fmt.Sprintf("attribute %q %s", a.Name, cause))
+		}
+
+		switch {
+		case a.Name == "":
+			return errs.NewError(errs.ErrInvalidAttributePattern, "the attribute name is required")
+		case reservedAttributes[strings.ToLower(a.Name)]:
+			return invalid("is the name of an employee field")
+		case names[a.Name]:
+			return invalid("is repeated")
+		case a.Column == "":
+			return invalid("has no column")
+		}
+		names[a.Name] = true
+
+		if a.Type == "" {
+			a.Type = AttributeString
+		}
+		switch a.Type {
+		case AttributeString, AttributeNumber, AttributeDate, AttributeBool:
+		default:
+			return invalid(fmt.Sprintf("has the unknown type %q", a.Type))
+		}
+
+		if (a.Pattern != "" || len(a.Values) != 0) && a.Type != AttributeString {
+			return invalid("can only have a pattern or values with the string type")
+		}
+		if (a.Min != nil || a.Max != nil) && a.Type != AttributeNumber {
+			return invalid("can only have a min or max with the number type")
+		}
+		if a.Min != nil && a.Max != nil && *a.Min > *a.Max {
+			return invalid("has a min greater than the max")
+		}
+		if a.Layout != "" && a.Type != AttributeDate {
+			return invalid("can only have a layout with the date type")
+		}
+
+		if a.Pattern != "" {
+			re, err := regexp.Compile(a.Pattern)
+			if err != nil {
+				return invalid(fmt.Sprintf("has an invalid pattern: %s", err))
+			}
+			a.re = re
+		}
+	}
+
+	return nil
+}
+
+// buildAttributes maps the attributes of the FilePattern from the line, returning the invalid ones as
+// errs.ValidationError with the "attributes.<name>" Field.
+func buildAttributes(employeeMap map[string]string, pattern *FilePattern, line int) (entity.Attributes, []*errs.ValidationError) {
+	var (
+		attributes     entity.Attributes
+		validationErrs []*errs.ValidationError
+	)
+	for _, a := range pattern.Attributes {
+		raw := employeeMap[a.Column]
+		value, err := a.parse(strings.TrimSpace(raw))
+		if err != nil {
+			validationErrs = append(validationErrs, newValidationError(attributeField(a.Name), a.Column, line, raw, err))
+			continue
+		}
+		if value == nil {
+			continue
+		}
+
+		if attributes == nil {
+			attributes = make(entity.Attributes, len(pattern.Attributes))
+		}
+		attributes[a.Name] = value
+	}
+
+	return attributes, validationErrs
+}
+
+// parse returns the typed value, nil for an empty value that is not Required.
+func (a *AttributePattern) parse(value string) (interface{}, error) {
+	if value == "" {
+		if a.Required {
+			return nil, a.invalid("is required")
+		}
+		return nil, nil
+	}
+
+	switch a.Type {
+	case AttributeNumber:
+		number, err := strconv.ParseFloat(value, 64)
+		// NaN and Inf are parsed but can not be encoded to JSON.
+		if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+			return nil, a.invalid("must be a number")
+		}
+		if a.Min != nil && number < *a.Min {
+			return nil, a.invalid(fmt.Sprintf("must not be less than %v", *a.Min))
+		}
+		if a.Max != nil && number > *a.Max {
+			return nil, a.invalid(fmt.Sprintf("must not be greater than %v", *a.Max))
+		}
+		return number, nil
+	case AttributeDate:
+		layout := a.Layout
+		if layout == "" {
+			layout = attributeDateLayout
+		}
+		date, err := time.Parse(layout, value)
+		if err != nil {
+			return nil, a.invalid(fmt.Sprintf("must be a date like %q", layout))
+		}
+		return date.Format(attributeDateLayout), nil
+	case AttributeBool:
+		switch strings.ToLower(value) {
+		case "yes", "y":
+			return true, nil
+		case "no", "n":
+			return false, nil
+		}
+		b, err := strconv.ParseBool(strings.ToLower(value))
+		if err != nil {
+			return nil, a.invalid("must be a bool")
+		}
+		return b, nil
+	default:
+		if a.re != nil && !a.re.MatchString(value) {
+			return nil, a.invalid(fmt.Sprintf("must match %q", a.Pattern))
+		}
+		if len(a.Values) != 0 && !containsString(a.Values, value) {
+			return nil, a.invalid(fmt.Sprintf("must be one of %s", strings.Join(a.Values, ", ")))
+		}
+		return value, nil
+	}
+}
+
+// attributeField returns the name of an attribute in the Lineage, the Diff and the entity.Provenance.
+func attributeField(name string) string {
+	return errs.FieldAttributes + "." + name
+}
+
+func (a *AttributePattern) invalid(cause string) error {
+	return errs.NewError(errs.ErrInvalidAttribute, fmt.Sprintf("%s %s", a.Name, cause))
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
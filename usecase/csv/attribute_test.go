@@ -0,0 +1,133 @@
+package csv_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vsantosalmeida/csv-parser/entity"
+	errs "github.com/vsantosalmeida/csv-parser/pkg/errors"
+	"github.com/vsantosalmeida/csv-parser/usecase/csv"
+)
+
+func newAttributePattern(attributes ...*csv.AttributePattern) *csv.FilePattern {
+	return &csv.FilePattern{
+		FirstNameColumn: "Name",
+		SalaryColumn:    "Wage",
+		EmailColumn:     "Email",
+		IDColumn:        "Number",
+		Attributes:      attributes,
+	}
+}
+
+func TestService_ParseFiles_Attributes(t *testing.T) {
+	var (
+		min, max     = float64(1), float64(5)
+		givenContent = "Name,Email,Wage,Number,Dept,Hired,Level,Remote,Badge\n" +
+			"John Doe,doe@test.com,$10,1,Sales,01/15/2020,3,yes,B-01\n" +
+			"Mary Jane,mary@test.com,$15,2,IT,,1,false,\n" +
+			"Max Topperson,max@test.com,$11,3,HR,2020-01-15,9,maybe,X\n" +
+			"Jane Doe,jane@test.com,$8,4,,03/01/2021,2,no,B-02\n" +
+			"Al Donald,al@test.com,$9,5,IT,,NaN,,\n" +
+			"Ed Stone,ed@test.com,$9,6,IT,,+Infinity,,\n"
+		givenPattern = newAttributePattern(
+			&csv.AttributePattern{Name: "department", Column: "Dept", Required: true, Values: []string{"Sales", "IT"}},
+			&csv.AttributePattern{Name: "hired", Column: "Hired", Type: csv.AttributeDate, Layout: "01/02/2006"},
+			&csv.AttributePattern{Name: "level", Column: "Level", Type: csv.AttributeNumber, Min: &min, Max: &max},
+			&csv.AttributePattern{Name: "remote", Column: "Remote", Type: csv.AttributeBool},
+			&csv.AttributePattern{Name: "badge", Column: "Badge", Pattern: `^B-\d+$`},
+		)
+		want = []*entity.Employee{
+			{
+				ID: "1", Email: "doe@test.com", Name: "John Doe", Salary: 10,
+				Attributes: entity.Attributes{"department": "Sales", "hired": "2020-01-15", "level": float64(3), "remote": true, "badge": "B-01"},
+			},
+			{
+				ID: "2", Email: "mary@test.com", Name: "Mary Jane", Salary: 15,
+				Attributes: entity.Attributes{"department": "IT", "level": float64(1), "remote": false},
+			},
+		}
+		wantReasons = map[string][]string{
+			"4": {
+				"the custom attribute value is invalid: department must be one of Sales, IT",
+				`the custom attribute value is invalid: hired must be a date like "01/02/2006"`,
+				"the custom attribute value is invalid: level must not be greater than 5",
+				"the custom attribute value is invalid: remote must be a bool",
+				`the custom attribute value is invalid: badge must match "^B-\\d+$"`,
+			},
+			"5": {"the custom attribute value is invalid: department is required"},
+			"6": {"the custom attribute value is invalid: level must be a number"},
+			"7": {"the custom attribute value is invalid: level must be a number"},
+		}
+	)
+
+	dir, err := ioutil.TempDir("", "attributes")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	givenFile := filepath.Join(dir, "roster.csv")
+	assert.NoError(t, ioutil.WriteFile(givenFile, []byte(givenContent), 0644))
+
+	svc, err := csv.NewParser(map[string]*csv.FilePattern{givenFile: givenPattern}, csv.WithOutputDir(dir))
+	assert.NoError(t, err)
+	assert.Empty(t, svc.ParseFiles([]string{givenFile}))
+
+	b, err := ioutil.ReadFile(svc.Summary().EmployeesFile)
+	assert.NoError(t, err)
+	var got []*entity.Employee
+	assert.NoError(t, json.Unmarshal(b, &got))
+	assert.Equal(t, want, got)
+
+	b, err = ioutil.ReadFile(svc.Summary().BadDataFile)
+	assert.NoError(t, err)
+	var gotBadData map[string][]*csv.BadData
+	assert.NoError(t, json.Unmarshal(b, &gotBadData))
+	gotReasons := make(map[string][]string)
+	for _, bd := range gotBadData[givenFile] {
+		gotReasons[bd.Line.String()] = bd.Reasons
+	}
+	assert.Equal(t, wantReasons, gotReasons)
+
+	// the attributes are compared by the diff.
+	previous := []*entity.Employee{{ID: "1", Email: "doe@test.com", Name: "John Doe", Salary: 10,
+		Attributes: entity.Attributes{"department": "IT", "hired": "2020-01-15", "level": float64(3), "remote": true, "manager": "7"}}}
+	diff := csv.DiffEmployees(previous, got[:1])
+	if assert.Len(t, diff.Changed, 1) {
+		assert.Equal(t, []*csv.FieldChange{
+			{Field: "attributes.badge", Old: nil, New: "B-01"},
+			{Field: "attributes.department", Old: "IT", New: "Sales"},
+			{Field: "attributes.manager", Old: "7", New: nil},
+		}, diff.Changed[0].Changes)
+	}
+}
+
+func TestNewParser_InvalidAttributes(t *testing.T) {
+	min, max := float64(5), float64(1)
+	tests := []struct {
+		name  string
+		given *csv.AttributePattern
+	}{
+		{name: "empty name", given: &csv.AttributePattern{Column: "Dept"}},
+		{name: "reserved name", given: &csv.AttributePattern{Name: "Email", Column: "Dept"}},
+		{name: "empty column", given: &csv.AttributePattern{Name: "department"}},
+		{name: "unknown type", given: &csv.AttributePattern{Name: "department", Column: "Dept", Type: "text"}},
+		{name: "invalid pattern", given: &csv.AttributePattern{Name: "department", Column: "Dept", Pattern: "("}},
+		{name: "values of a number", given: &csv.AttributePattern{Name: "level", Column: "Level", Type: csv.AttributeNumber, Values: []string{"1"}}},
+		{name: "min greater than max", given: &csv.AttributePattern{Name: "level", Column: "Level", Type: csv.AttributeNumber, Min: &min, Max: &max}},
+		{name: "layout of a string", given: &csv.AttributePattern{Name: "hired", Column: "Hired", Layout: "2006"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, err := csv.NewParser(map[string]*csv.FilePattern{"roster.csv": newAttributePattern(tt.given)})
+			assert.Nil(t, svc)
+			assert.ErrorIs(t, err, errs.ErrInvalidAttributePattern)
+		})
+	}
+
+	repeated := &csv.AttributePattern{Name: "department", Column: "Dept"}
+	_, err := csv.NewParser(map[string]*csv.FilePattern{"roster.csv": newAttributePattern(repeated, repeated)})
+	assert.ErrorIs(t, err, errs.ErrInvalidAttributePattern)
+}
@@ -1,8 +1,18 @@
 package csv
 
-import "encoding/json"
+import (
+	"encoding/json"
 
+	errs "github.com/vsantosalmeida/csv-parser/pkg/errors"
+)
+
+// BadData is a line that could not be processed, Reasons holds the messages written to the result file
+// and Errors the same failures as *errs.ValidationError, with the field, line and value of each one.
+//
+// ID is the value of the ID column of the line, empty when the line is malformed or has no ID.
 type BadData struct {
-	Line    json.Number `json:"line"`
-	Reasons []string    `json:"reasons"`
+	Line    json.Number             `json:"line"`
+	Reasons []string                `json:"reasons"`
+	Errors  []*errs.ValidationError `json:"-"`
+	ID      string                  `json:"-"`
 }
@@ -0,0 +1,180 @@
+package csv
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	errs "github.com/vsantosalmeida/csv-parser/pkg/errors"
+)
+
+// DelimiterAuto is the Dialect.Delimiter value to detect the delimiter from the first lines with SniffDelimiter.
+const DelimiterAuto = "auto"
+
+// sniffSize is the number of bytes read from the beginning of a file to detect its delimiter.
+const sniffSize = 16 * 1024
+
+// sniffCandidates are the delimiters checked by SniffDelimiter, sorted by priority.
+var sniffCandidates = []rune{',', ';', '\t', '|'}
+
+// Dialect configures how the CSV reader splits the lines and fields of a file,
+// the zero value reads a file with the encoding/csv defaults.
+type Dialect struct {
+	// Delimiter is the field separator, "," when empty or detected from the file when DelimiterAuto.
+	Delimiter string `json:"delimiter,omitempty"`
+	// Comment is the char at the beginning of a line to ignore it, like "#", none when empty.
+	Comment string `json:"comment,omitempty"`
+	// LazyQuotes allows quotes in an unquoted field and non-doubled quotes in a quoted field.
+	LazyQuotes bool `json:"lazy_quotes,omitempty"`
+	// TrimLeadingSpace ignores the leading white spaces of each field.
+	TrimLeadingSpace bool `json:"trim_leading_space,omitempty"`
+	// FieldsPerRecord is the number of fields of each line, 0 requires the same number of fields as the header,
+	// a negative value allows any number of fields and a positive value requires the given number of fields.
+	FieldsPerRecord int `json:"fields_per_record,omitempty"`
+}
+
+func validateDialect(d *Dialect) error {
+	if d == nil {
+		return nil
+	}
+
+	delimiter := ","
+	if d.Delimiter != "" && d.Delimiter != DelimiterAuto {
+		r, err := dialectRune("delimiter", d.Delimiter)
+		if err != nil {
+			return err
+		}
+
+		if !validDelimiter(r) {
+			return errs.NewError(errs.ErrInvalidDialect, fmt.Sprintf("%q can not be used as delimiter", d.Delimiter))
+		}
+		delimiter = d.Delimiter
+	}
+
+	if d.Comment != "" {
+		r, err := dialectRune("comment", d.Comment)
+		if err != nil {
+			return err
+		}
+
+		if !validDelimiter(r) {
+			return errs.NewError(errs.ErrInvalidDialect, fmt.Sprintf("%q can not be used as comment", d.Comment))
+		}
+
+		if d.Comment == delimiter {
+			return errs.NewError(errs.ErrInvalidDialect, "the comment and delimiter must be different")
+		}
+	}
+
+	return nil
+}
+
+func dialectRune(name, value string) (rune, error) {
+	r, size := utf8.DecodeRuneInString(value)
+	if r == utf8.RuneError || size != len(value) {
+		return 0, errs.NewError(errs.ErrInvalidDialect, fmt.Sprintf("the %s must be a single char", name))
+	}
+
+	return r, nil
+}
+
+// validDelimiter follows the encoding/csv rules for the Comma and Comment chars.
+func validDelimiter(r rune) bool {
+	return r != 0 && r != '"' && r != '\r' && r != '\n' && utf8.ValidRune(r) && r != utf8.RuneError
+}
+
+// newReader creates a *csv.Reader configured by the Dialect, a nil Dialect uses the encoding/csv defaults.
+//
+// When the delimiter is DelimiterAuto the beginning of r is read to detect it.
+func newReader(r io.Reader, d *Dialect) (*csv.Reader, error) {
+	if d == nil {
+		return csv.NewReader(r), nil
+	}
+
+	var delimiter rune
+	switch d.Delimiter {
+	case "":
+		delimiter = ','
+	case DelimiterAuto:
+		br := bufio.NewReaderSize(r, sniffSize)
+		sample, err := br.Peek(sniffSize)
+		if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+			return nil, err
+		}
+		delimiter = SniffDelimiter(sample, d.Comment)
+		r = br
+	default:
+		delimiter, _ = utf8.DecodeRuneInString(d.Delimiter)
+	}
+
+	reader := csv.NewReader(r)
+	reader.Comma = delimiter
+	if d.Comment != "" {
+		reader.Comment, _ = utf8.DecodeRuneInString(d.Comment)
+	}
+	reader.LazyQuotes = d.LazyQuotes
+	reader.TrimLeadingSpace = d.TrimLeadingSpace
+	if d.FieldsPerRecord < 0 {
+		reader.FieldsPerRecord = -1
+	} else {
+		reader.FieldsPerRecord = d.FieldsPerRecord
+	}
+
+	return reader, nil
+}
+
+// SniffDelimiter detects the delimiter of a CSV sample, usually the first lines of a file.
+//
+// Each candidate (",", ";", tab and "|") is counted out of quotes in every line, ignoring the lines starting
+// with the comment char, and the one found the same number of times in most lines wins, "," when none is found.
+func SniffDelimiter(sample []byte, comment string) rune {
+	lines := bytes.Split(sample, []byte("\n"))
+	// the last line may be cut by the sample size.
+	if len(lines) > 1 {
+		lines = lines[:len(lines)-1]
+	}
+
+	best, bestScore := ',', 0
+	for _, candidate := range sniffCandidates {
+		counts := make(map[int]int)
+		for _, line := range lines {
+			line = bytes.TrimRight(line, "\r")
+			if len(bytes.TrimSpace(line)) == 0 || (comment != "" && bytes.HasPrefix(line, []byte(comment))) {
+				continue
+			}
+			if n := countOutOfQuotes(line, candidate); n > 0 {
+				counts[n]++
+			}
+		}
+
+		// the score is the number of lines sharing the most common count, so a delimiter
+		// found the same number of times in each line wins over one found many times in few lines.
+		for _, score := range counts {
+			if score > bestScore {
+				best, bestScore = candidate, score
+			}
+		}
+	}
+
+	return best
+}
+
+func countOutOfQuotes(line []byte, delimiter rune) int {
+	var (
+		count  int
+		quoted bool
+	)
+	for _, r := range string(line) {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == delimiter && !quoted:
+			count++
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,70 @@
+package csv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vsantosalmeida/csv-parser/pkg/errors"
+)
+
+func TestValidateDialect(t *testing.T) {
+	tt := []struct {
+		name         string
+		givenDialect *Dialect
+		wantErr      error
+	}{
+		{
+			name: "Nil dialect",
+		},
+		{
+			name:         "Tab delimiter and comment",
+			givenDialect: &Dialect{Delimiter: "\t", Comment: "#", FieldsPerRecord: -1},
+		},
+		{
+			name:         "Auto delimiter",
+			givenDialect: &Dialect{Delimiter: DelimiterAuto},
+		},
+		{
+			name:         "More than a char delimiter",
+			givenDialect: &Dialect{Delimiter: ";;"},
+			wantErr:      errors.ErrInvalidDialect,
+		},
+		{
+			name:         "Quote delimiter",
+			givenDialect: &Dialect{Delimiter: `"`},
+			wantErr:      errors.ErrInvalidDialect,
+		},
+		{
+			name:         "New line comment",
+			givenDialect: &Dialect{Comment: "\n"},
+			wantErr:      errors.ErrInvalidDialect,
+		},
+		{
+			name:         "Comment equals to the default delimiter",
+			givenDialect: &Dialect{Comment: ","},
+			wantErr:      errors.ErrInvalidDialect,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateDialect(tc.givenDialect)
+			if tc.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestNewReader_AutoDelimiter(t *testing.T) {
+	givenFile := "id;name\n1;John\n2;Mary\n"
+
+	reader, err := newReader(strings.NewReader(givenFile), &Dialect{Delimiter: DelimiterAuto})
+	assert.NoError(t, err)
+
+	records, err := reader.ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"id", "name"}, {"1", "John"}, {"2", "Mary"}}, records)
+}
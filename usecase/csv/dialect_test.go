@@ -0,0 +1,59 @@
+package csv_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vsantosalmeida/csv-parser/usecase/csv"
+)
+
+func TestSniffDelimiter(t *testing.T) {
+	tt := []struct {
+		name         string
+		givenSample  string
+		givenComment string
+		want         rune
+	}{
+		{
+			name:        "Comma",
+			givenSample: "Name,Email,Wage,Number\nJohn Doe,doe@test.com,$10.00,1\n",
+			want:        ',',
+		},
+		{
+			name:        "Semicolon with decimal commas",
+			givenSample: "name;salary;email\nJohn;10,5;doe@test.com\nMary;15,25;mary@tes.com\n",
+			want:        ';',
+		},
+		{
+			name:        "Tab",
+			givenSample: "name\tsalary\nJohn, Doe\t10\nMary\t15\n",
+			want:        '\t',
+		},
+		{
+			name:        "Pipe",
+			givenSample: "name|salary\nJohn|10\n",
+			want:        '|',
+		},
+		{
+			name:        "Delimiter inside quotes",
+			givenSample: "name;salary\n\"Doe, John\";10\n\"Jane, Mary\";15\n",
+			want:        ';',
+		},
+		{
+			name:         "Comment lines are ignored",
+			givenSample:  "# exported, by HR, at 2021-01-01\nname;salary\nJohn;10\n",
+			givenComment: "#",
+			want:         ';',
+		},
+		{
+			name:        "Single column",
+			givenSample: "name\nJohn\n",
+			want:        ',',
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, string(tc.want), string(csv.SniffDelimiter([]byte(tc.givenSample), tc.givenComment)))
+		})
+	}
+}
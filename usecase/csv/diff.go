@@ -0,0 +1,173 @@
+package csv
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vsantosalmeida/csv-parser/entity"
+	errs "github.com/vsantosalmeida/csv-parser/pkg/errors"
+)
+
+const writeDiffFile = "writeDiffFile"
+
+// Diff holds the employees added, removed and changed from a previous snapshot, each sorted by ID.
+type Diff struct {
+	Added   []*entity.Employee `json:"added"`
+	Removed []*entity.Employee `json:"removed"`
+	Changed []*EmployeeChange  `json:"changed"`
+	// Unchanged is the number of employees equal in both snapshots.
+	Unchanged int `json:"unchanged"`
+}
+
+// EmployeeChange holds the changed fields of an employee found in both snapshots by its ID.
+type EmployeeChange struct {
+	ID       string           `json:"id"`
+	Changes  []*FieldChange   `json:"changes"`
+	Employee *entity.Employee `json:"employee"`
+}
+
+// FieldChange is the previous and the current value of an employee field, named like its JSON key, or of a
+// custom attribute, named like "attributes.department" with a nil value when the employee does not have it.
+type FieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// WithDiff compares the employees written by Parser.ParseFiles with the previous snapshot by ID, writing
+// the Diff to a diff result file. The diff is not written when the employees file is not.
+func WithDiff(previous []*entity.Employee) Option {
+	return func(s *service) {
+		if previous == nil {
+			previous = []*entity.Employee{}
+		}
+		s.previous = previous
+	}
+}
+
+// LoadEmployeesFile reads an employees result file written in JSON or NDJSON, like a previous snapshot.
+func LoadEmployeesFile(path string) ([]*entity.Employee, error) {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if format != FormatJSON && format != FormatNDJSON {
+		return nil, errs.NewError(errs.ErrUnsupportedFormat, path)
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, &errs.FileError{Path: path, Op: errs.OpOpen, Err: errs.NewError(errs.ErrOpeningFile, err.Error())}
+	}
+
+	employees, badData, err := decodeResultFile(format, b)
+	if err == nil && badData != nil {
+		err = errs.NewError(errs.ErrUnsupportedFormat, "a bad data file is not an employees snapshot")
+	}
+	if err != nil {
+		return nil, &errs.FileError{Path: path, Op: errs.OpRead, Err: errs.NewError(errs.ErrReadingFile, err.Error())}
+	}
+
+	return employees, nil
+}
+
+// DiffEmployees compares the current employees with the previous ones by ID.
+func DiffEmployees(previous, current []*entity.Employee) *Diff {
+	diff := &Diff{
+		Added:   []*entity.Employee{},
+		Removed: []*entity.Employee{},
+		Changed: []*EmployeeChange{},
+	}
+
+	previousByID := make(map[string]*entity.Employee, len(previous))
+	for _, employee := range previous {
+		previousByID[employee.ID] = employee
+	}
+
+	for _, employee := range current {
+		old, ok := previousByID[employee.ID]
+		if !ok {
+			diff.Added = append(diff.Added, employee)
+			continue
+		}
+		delete(previousByID, employee.ID)
+
+		if changes := diffFields(old, employee); len(changes) != 0 {
+			diff.Changed = append(diff.Changed, &EmployeeChange{ID: employee.ID, Changes: changes, Employee: employee})
+		} else {
+			diff.Unchanged++
+		}
+	}
+
+	for _, employee := range previous {
+		if _, ok := previousByID[employee.ID]; ok {
+			diff.Removed = append(diff.Removed, employee)
+		}
+	}
+
+	sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i].ID < diff.Added[j].ID })
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].ID < diff.Removed[j].ID })
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].ID < diff.Changed[j].ID })
+
+	return diff
+}
+
+func diffFields(old, current *entity.Employee) []*FieldChange {
+	var changes []*FieldChange
+	add := func(field string, oldValue, newValue interface{}) {
+		if oldValue != newValue {
+			changes = append(changes, &FieldChange{Field: field, Old: oldValue, New: newValue})
+		}
+	}
+
+	add("email", old.Email, current.Email)
+	add("name", old.Name, current.Name)
+	add("salary", old.Salary, current.Salary)
+	add("phone", old.Phone, current.Phone)
+
+	names := current.Attributes.Names()
+	for _, name := range old.Attributes.Names() {
+		if _, ok := current.Attributes[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		add(attributeField(name), old.Attributes[name], current.Attributes[name])
+	}
+
+	return changes
+}
+
+// writeDiffResultFile compares the employees with the previous snapshot and writes the Diff.
+func (s *service) writeDiffResultFile(employees []*entity.Employee) (*Diff, string, error) {
+	diff := DiffEmployees(s.previous, employees)
+
+	file, err := json.MarshalIndent(diff, "", " ")
+	if err != nil {
+		return nil, "", err
+	}
+
+	fileName := s.resultFileName("diff")
+	if err := s.writeResultFile(fileName, file); err != nil {
+		log.WithFields(log.Fields{
+			"event":  "write_diff_file_failed",
+			"file":   fileName,
+			"reason": err,
+		}).Error()
+		return nil, fileName, err
+	}
+
+	log.WithFields(log.Fields{
+		"event":   "diff_result_file_wrote",
+		"file":    fileName,
+		"added":   len(diff.Added),
+		"removed": len(diff.Removed),
+		"changed": len(diff.Changed),
+	}).Info()
+
+	return diff, fileName, nil
+}
@@ -0,0 +1,107 @@
+package csv_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vsantosalmeida/csv-parser/entity"
+	errs "github.com/vsantosalmeida/csv-parser/pkg/errors"
+	"github.com/vsantosalmeida/csv-parser/usecase/csv"
+)
+
+func TestDiffEmployees(t *testing.T) {
+	var (
+		givenPrevious = []*entity.Employee{
+			{ID: "2", Email: "mary@test.com", Name: "Mary Jane", Salary: 15},
+			{ID: "1", Email: "doe@test.com", Name: "John Doe", Salary: 10},
+			{ID: "9", Email: "old@test.com", Name: "Old Timer", Salary: 20},
+		}
+		givenCurrent = []*entity.Employee{
+			{ID: "1", Email: "doe@test.com", Name: "John Doe", Salary: 10},
+			{ID: "3", Email: "max@test.com", Name: "Max Topperson", Salary: 11},
+			{ID: "2", Email: "mary@test.com", Name: "Mary J.", Salary: 16, Phone: "555"},
+		}
+
+		want = &csv.Diff{
+			Added:   []*entity.Employee{givenCurrent[1]},
+			Removed: []*entity.Employee{givenPrevious[2]},
+			Changed: []*csv.EmployeeChange{
+				{
+					ID: "2",
+					Changes: []*csv.FieldChange{
+						{Field: "name", Old: "Mary Jane", New: "Mary J."},
+						{Field: "salary", Old: float64(15), New: float64(16)},
+						{Field: "phone", Old: "", New: "555"},
+					},
+					Employee: givenCurrent[2],
+				},
+			},
+			Unchanged: 1,
+		}
+	)
+
+	assert.Equal(t, want, csv.DiffEmployees(givenPrevious, givenCurrent))
+}
+
+func TestService_ParseFiles_Diff(t *testing.T) {
+	var (
+		givenFile         = "test_files/roster1.csv"
+		givenFilePatterns = map[string]*csv.FilePattern{
+			givenFile: {
+				FirstNameColumn: "Name",
+				SalaryColumn:    "Wage",
+				EmailColumn:     "Email",
+				IDColumn:        "Number",
+			},
+		}
+		givenPrevious = []*entity.Employee{
+			{ID: "1", Email: "doe@test.com", Name: "John Doe", Salary: 10},
+			{ID: "2", Email: "Mary@tes.com", Name: "Mary Jane", Salary: 12},
+			{ID: "4", Email: "alfred@test.com", Name: "Alfred Donald", Salary: 11.5},
+		}
+	)
+
+	dir, err := ioutil.TempDir("", "diff")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	svc, err := csv.NewParser(givenFilePatterns, csv.WithOutputDir(dir), csv.WithDiff(givenPrevious))
+	assert.NoError(t, err)
+	assert.Empty(t, svc.ParseFiles([]string{givenFile}))
+
+	summary := svc.Summary()
+	if !assert.NotNil(t, summary.Diff) {
+		return
+	}
+	assert.Equal(t, 1, summary.Diff.Added)
+	assert.Equal(t, 1, summary.Diff.Removed)
+	assert.Equal(t, 1, summary.Diff.Changed)
+	assert.Equal(t, 1, summary.Diff.Unchanged)
+	assert.Equal(t, dir, filepath.Dir(summary.Diff.File))
+
+	b, err := ioutil.ReadFile(summary.Diff.File)
+	assert.NoError(t, err)
+	var got csv.Diff
+	assert.NoError(t, json.Unmarshal(b, &got))
+	assert.Equal(t, "3", got.Added[0].ID)
+	assert.Equal(t, "4", got.Removed[0].ID)
+	assert.Equal(t, &csv.FieldChange{Field: "salary", Old: float64(12), New: float64(15)}, got.Changed[0].Changes[0])
+
+	// the previous snapshot can be the employees file of the run.
+	previous, err := csv.LoadEmployeesFile(summary.EmployeesFile)
+	assert.NoError(t, err)
+	assert.Empty(t, csv.DiffEmployees(previous, previous).Changed)
+	assert.Equal(t, 3, csv.DiffEmployees(previous, previous).Unchanged)
+}
+
+func TestLoadEmployeesFile_Error(t *testing.T) {
+	_, err := csv.LoadEmployeesFile("test_files/roster1.csv")
+	assert.ErrorIs(t, err, errs.ErrUnsupportedFormat)
+
+	_, err = csv.LoadEmployeesFile("not_found.json")
+	assert.ErrorIs(t, err, errs.ErrOpeningFile)
+}
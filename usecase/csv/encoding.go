@@ -0,0 +1,146 @@
+package csv
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	errs "github.com/vsantosalmeida/csv-parser/pkg/errors"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// Encodings supported by FilePattern.Encoding, the file is transcoded to UTF-8 before being read.
+const (
+	// EncodingAuto detects the encoding by the BOM, or from the first bytes of the file when there is no BOM.
+	EncodingAuto        = "auto"
+	EncodingUTF8        = "utf-8"
+	EncodingUTF16LE     = "utf-16le"
+	EncodingUTF16BE     = "utf-16be"
+	EncodingLatin1      = "latin1"
+	EncodingWindows1252 = "windows-1252"
+)
+
+var (
+	utf8BOM    = []byte{0xEF, 0xBB, 0xBF}
+	utf16LEBOM = []byte{0xFF, 0xFE}
+	utf16BEBOM = []byte{0xFE, 0xFF}
+)
+
+var encodings = map[string]encoding.Encoding{
+	EncodingUTF8:        unicode.UTF8BOM,
+	EncodingUTF16LE:     unicode.UTF16(unicode.LittleEndian, unicode.UseBOM),
+	EncodingUTF16BE:     unicode.UTF16(unicode.BigEndian, unicode.UseBOM),
+	EncodingLatin1:      charmap.ISO8859_1,
+	EncodingWindows1252: charmap.Windows1252,
+}
+
+func validateEncoding(name string) error {
+	if name == "" || strings.EqualFold(name, EncodingAuto) {
+		return nil
+	}
+
+	if _, ok := encodings[strings.ToLower(name)]; !ok {
+		return errs.NewError(errs.ErrInvalidEncoding, name)
+	}
+
+	return nil
+}
+
+// newDecoder returns a reader transcoding r from the named encoding to UTF-8 without the BOM,
+// an empty name is the same as EncodingAuto.
+//
+// The bytes that can not be decoded are replaced by utf8.RuneError, reported later by invalidEncoding.
+func newDecoder(r io.Reader, name string) (io.Reader, string, error) {
+	name = strings.ToLower(name)
+	if name == "" || name == EncodingAuto {
+		br := bufio.NewReaderSize(r, sniffSize)
+		sample, err := br.Peek(sniffSize)
+		if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+			return nil, "", err
+		}
+		name, r = DetectEncoding(sample), br
+	}
+
+	enc, ok := encodings[name]
+	if !ok {
+		return nil, "", errs.NewError(errs.ErrInvalidEncoding, name)
+	}
+
+	return transform.NewReader(r, enc.NewDecoder()), name, nil
+}
+
+// DetectEncoding detects the encoding of a sample, usually the first bytes of a file.
+//
+// A BOM has priority, otherwise a sample with NUL bytes in most odd or even positions is UTF-16,
+// a valid UTF-8 sample is UTF-8 and anything else is Windows-1252, the usual encoding of Excel exports.
+//
+// The detection never fails: Windows-1252 decodes every byte but 0x81, 0x8D, 0x8F, 0x90 and 0x9D, so only the
+// lines with these bytes are reported as undecodable and a Latin-1 or Shift-JIS file is read as Windows-1252.
+// The FilePattern.Encoding must be set to check strictly that the file has the expected encoding.
+func DetectEncoding(sample []byte) string {
+	switch {
+	case bytes.HasPrefix(sample, utf8BOM):
+		return EncodingUTF8
+	case bytes.HasPrefix(sample, utf16LEBOM):
+		return EncodingUTF16LE
+	case bytes.HasPrefix(sample, utf16BEBOM):
+		return EncodingUTF16BE
+	}
+
+	var evenNULs, oddNULs int
+	for i, b := range sample {
+		if b != 0 {
+			continue
+		}
+		if i%2 == 0 {
+			evenNULs++
+		} else {
+			oddNULs++
+		}
+	}
+
+	half := len(sample) / 4
+	switch {
+	case half > 0 && oddNULs > half:
+		return EncodingUTF16LE
+	case half > 0 && evenNULs > half:
+		return EncodingUTF16BE
+	}
+
+	// the sample may cut the last rune of the file.
+	if !utf8.Valid(sample) {
+		if end := lastRuneStart(sample); end > 0 && utf8.Valid(sample[:end]) {
+			return EncodingUTF8
+		}
+		return EncodingWindows1252
+	}
+
+	return EncodingUTF8
+}
+
+func lastRuneStart(b []byte) int {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// invalidEncoding returns the index of the first field of the record with bytes that could not be decoded,
+// -1 when all the fields are valid.
+func invalidEncoding(record []string) int {
+	for i, field := range record {
+		if !utf8.ValidString(field) || strings.ContainsRune(field, utf8.RuneError) {
+			return i
+		}
+	}
+
+	return -1
+}
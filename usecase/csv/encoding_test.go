@@ -0,0 +1,67 @@
+package csv_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vsantosalmeida/csv-parser/usecase/csv"
+)
+
+func TestDetectEncoding(t *testing.T) {
+	tt := []struct {
+		name        string
+		givenSample []byte
+		want        string
+	}{
+		{
+			name:        "UTF-8 BOM",
+			givenSample: []byte("\xef\xbb\xbfID,Name"),
+			want:        csv.EncodingUTF8,
+		},
+		{
+			name:        "UTF-16LE BOM",
+			givenSample: []byte("\xff\xfeI\x00D\x00"),
+			want:        csv.EncodingUTF16LE,
+		},
+		{
+			name:        "UTF-16BE BOM",
+			givenSample: []byte("\xfe\xff\x00I\x00D"),
+			want:        csv.EncodingUTF16BE,
+		},
+		{
+			name:        "UTF-16LE without BOM",
+			givenSample: []byte("I\x00D\x00,\x00N\x00a\x00m\x00e\x00"),
+			want:        csv.EncodingUTF16LE,
+		},
+		{
+			name:        "UTF-16BE without BOM",
+			givenSample: []byte("\x00I\x00D\x00,\x00N\x00a\x00m\x00e"),
+			want:        csv.EncodingUTF16BE,
+		},
+		{
+			name:        "UTF-8 without BOM",
+			givenSample: []byte("ID,Name\nRT1,Jos\xc3\xa9"),
+			want:        csv.EncodingUTF8,
+		},
+		{
+			name:        "UTF-8 with the last rune cut",
+			givenSample: []byte("ID,Name\nRT1,Jos\xc3"),
+			want:        csv.EncodingUTF8,
+		},
+		{
+			name:        "Windows-1252",
+			givenSample: []byte("ID,Name\nRT1,Jos\xe9 \xc1lvarez\n"),
+			want:        csv.EncodingWindows1252,
+		},
+		{
+			name:        "Shift-JIS read as Windows-1252",
+			givenSample: []byte("ID,Name\nRT1,\x93\x63\x92\x86\n"),
+			want:        csv.EncodingWindows1252,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, csv.DetectEncoding(tc.givenSample))
+		})
+	}
+}
@@ -2,9 +2,14 @@ package csv
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"path"
+	"path/filepath"
 	"strings"
+	"unicode"
 
 	log "github.com/sirupsen/logrus"
 	errs "github.com/vsantosalmeida/csv-parser/pkg/errors"
@@ -12,12 +17,126 @@ import (
 
 // FilePattern it's a struct to translate the columns from a CSV file to map as an entity.Employee.
 type FilePattern struct {
-	FirstNameColumn string
-	LastNameColumn  string
-	SalaryColumn    string
-	EmailColumn     string
-	IDColumn        string
-	PhoneColumn     string
+	FirstNameColumn string `json:"first_name"`
+	LastNameColumn  string `json:"last_name,omitempty"`
+	SalaryColumn    string `json:"salary"`
+	EmailColumn     string `json:"email"`
+	IDColumn        string `json:"id"`
+	PhoneColumn     string `json:"phone,omitempty"`
+	// Dialect configures how the file is read, nil uses the encoding/csv defaults.
+	Dialect *Dialect `json:"dialect,omitempty"`
+	// Encoding is the character encoding of the file, like EncodingWindows1252, detected when empty.
+	Encoding string `json:"encoding,omitempty"`
+	// Format of the file, FormatCSV, FormatXLSX, FormatJSON, FormatNDJSON, FormatXML or FormatFixedWidth,
+	// from the file extension when empty. For the JSON formats the columns are the object keys or dotted paths to the nested fields,
+	// like "contact.email", and for FormatXML the paths from the Element, like "name/first" or "@id".
+	Format Format `json:"format,omitempty"`
+	// Sheet configures how a FormatXLSX file is read, nil reads the first sheet.
+	Sheet *Sheet `json:"sheet,omitempty"`
+	// Element is the name of the repeating element of a FormatXML file, each child of the root element when empty.
+	Element string `json:"element,omitempty"`
+	// FixedWidth configures the columns of a FormatFixedWidth file, the Format can be omitted when it is given.
+	FixedWidth *FixedWidth `json:"fixed_width,omitempty"`
+	// Attributes maps the extra columns to the custom attributes of the employee.
+	Attributes []*AttributePattern `json:"attributes,omitempty"`
+}
+
+// columns returns the names of the mapped columns, without the empty and repeated ones.
+func (p *FilePattern) columns() []string {
+	var columns []string
+	used := make(map[string]bool)
+	all := []string{p.FirstNameColumn, p.LastNameColumn, p.SalaryColumn, p.EmailColumn, p.IDColumn, p.PhoneColumn}
+	for _, a := range p.Attributes {
+		all = append(all, a.Column)
+	}
+	for _, column := range all {
+		if column != "" && !used[column] {
+			columns = append(columns, column)
+			used[column] = true
+		}
+	}
+
+	return columns
+}
+
+// findPattern returns the FilePattern of the file key, or of the member path for an archive member,
+// or of the file name, like "roster1.csv" for "inbox/roster1.csv".
+//
+// When none is found the keys with a glob are matched, like "drop/roster*.csv" with the file path or "*.xlsx"
+// with the file name when the glob has no "/", and the most specific matching glob is used.
+func findPattern(patterns map[string]*FilePattern, input *inputFile) (*FilePattern, bool) {
+	if pattern, ok := patterns[input.key]; ok {
+		return pattern, true
+	}
+	if pattern, ok := patterns[input.member]; ok && input.member != "" {
+		return pattern, true
+	}
+
+	key := filepath.ToSlash(input.key)
+	name := path.Base(key)
+	var targets []string
+	if input.member != "" {
+		targets = []string{key, input.member}
+		name = path.Base(input.member)
+	} else {
+		targets = []string{key}
+	}
+
+	if pattern, ok := patterns[name]; ok {
+		return pattern, true
+	}
+
+	var found string
+	for glob := range patterns {
+		if !isGlob(glob) || !moreSpecificGlob(glob, found) {
+			continue
+		}
+
+		if !strings.Contains(glob, "/") {
+			if ok, _ := path.Match(glob, name); ok {
+				found = glob
+			}
+			continue
+		}
+		for _, target := range targets {
+			if ok, _ := path.Match(glob, target); ok {
+				found = glob
+				break
+			}
+		}
+	}
+	if found == "" {
+		return nil, false
+	}
+
+	log.WithFields(log.Fields{
+		"event": "file_pattern_matched_by_glob",
+		"file":  input.key,
+		"glob":  found,
+	}).Debug()
+	return patterns[found], true
+}
+
+// moreSpecificGlob reports if glob is preferred over found, the globs of a path are preferred over the
+// globs of a name, then the longest glob and the first by name.
+func moreSpecificGlob(glob, found string) bool {
+	if found == "" {
+		return true
+	}
+
+	globPath, foundPath := strings.Contains(glob, "/"), strings.Contains(found, "/")
+	if globPath != foundPath {
+		return globPath
+	}
+	if len(glob) != len(found) {
+		return len(glob) > len(found)
+	}
+
+	return glob < found
+}
+
+func isGlob(key string) bool {
+	return strings.ContainsAny(key, "*?[")
 }
 
 // NewFilePatternMap with each file in the []string will build a *FilePattern to process the received CSV file,
@@ -31,28 +150,28 @@ func NewFilePatternMap(files []string) map[string]*FilePattern {
 			"file":  file,
 		}).Info("")
 
-		fmt.Println("If file dont have the column name, just hit enter")
-		fmt.Println("Enter First Name column name:")
+		fmt.Fprintln(os.Stderr, "If file dont have the column name, just hit enter")
+		fmt.Fprintln(os.Stderr, "Enter First Name column name:")
 		firstName, _ := reader.ReadString('\n')
 		firstName = strings.TrimSuffix(firstName, "\n")
 
-		fmt.Println("Enter Last Name column name:")
+		fmt.Fprintln(os.Stderr, "Enter Last Name column name:")
 		lastName, _ := reader.ReadString('\n')
 		lastName = strings.TrimSuffix(lastName, "\n")
 
-		fmt.Println("Enter Salary column name:")
+		fmt.Fprintln(os.Stderr, "Enter Salary column name:")
 		salary, _ := reader.ReadString('\n')
 		salary = strings.TrimSuffix(salary, "\n")
 
-		fmt.Println("Enter Email column name:")
+		fmt.Fprintln(os.Stderr, "Enter Email column name:")
 		email, _ := reader.ReadString('\n')
 		email = strings.TrimSuffix(email, "\n")
 
-		fmt.Println("Enter ID column name:")
+		fmt.Fprintln(os.Stderr, "Enter ID column name:")
 		id, _ := reader.ReadString('\n')
 		id = strings.TrimSuffix(id, "\n")
 
-		fmt.Println("Enter Phone column name:")
+		fmt.Fprintln(os.Stderr, "Enter Phone column name:")
 		phone, _ := reader.ReadString('\n')
 		phone = strings.TrimSuffix(phone, "\n")
 
@@ -69,16 +188,211 @@ func NewFilePatternMap(files []string) map[string]*FilePattern {
 	return patterns
 }
 
+// LoadFilePatternMap reads a JSON file with the FilePattern of each CSV file, using the file name as the key:
+//
+//	{"roster1.csv": {"first_name": "Name", "salary": "Wage", "email": "Email", "id": "Number"}}
+func LoadFilePatternMap(path string) (map[string]*FilePattern, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, &errs.FileError{Path: path, Op: errs.OpOpen, Err: errs.NewError(errs.ErrOpeningFile, err.Error())}
+	}
+	defer file.Close()
+
+	patterns := make(map[string]*FilePattern)
+	if err := json.NewDecoder(file).Decode(&patterns); err != nil {
+		return nil, &errs.FileError{Path: path, Op: errs.OpRead, Err: errs.NewError(errs.ErrInvalidFilePatternConfig, err.Error())}
+	}
+
+	return patterns, nil
+}
+
+// columnSynonyms holds the known names of each column, normalized by normalizeColumnName and sorted by priority.
+var columnSynonyms = []struct {
+	names  []string
+	column func(p *FilePattern) *string
+}{
+	{
+		names:  []string{"firstname", "first", "fname", "givenname", "name", "fullname", "employeename"},
+		column: func(p *FilePattern) *string { return &p.FirstNameColumn },
+	},
+	{
+		names:  []string{"lastname", "last", "lname", "surname", "familyname"},
+		column: func(p *FilePattern) *string { return &p.LastNameColumn },
+	},
+	{
+		names:  []string{"salary", "wage", "rate", "pay", "payrate", "hourlyrate"},
+		column: func(p *FilePattern) *string { return &p.SalaryColumn },
+	},
+	{
+		names:  []string{"email", "mail", "emailaddress"},
+		column: func(p *FilePattern) *string { return &p.EmailColumn },
+	},
+	{
+		names:  []string{"id", "employeeid", "empid", "employeenumber", "empnumber", "empno", "number"},
+		column: func(p *FilePattern) *string { return &p.IDColumn },
+	},
+	{
+		names:  []string{"phone", "mobile", "cell", "telephone", "phonenumber", "mobilenumber"},
+		column: func(p *FilePattern) *string { return &p.PhoneColumn },
+	},
+}
+
+// InferFilePattern suggests a FilePattern from the columns' names of a CSV header,
+// a column is left empty when none of the names is known.
+func InferFilePattern(header []string) *FilePattern {
+	pattern := &FilePattern{}
+	used := make(map[string]bool)
+
+	for _, synonyms := range columnSynonyms {
+		column := synonyms.column(pattern)
+	lookup:
+		for _, name := range synonyms.names {
+			for _, columnName := range header {
+				if !used[columnName] && normalizeColumnName(columnName) == name {
+					*column = columnName
+					used[columnName] = true
+					break lookup
+				}
+			}
+		}
+	}
+
+	return pattern
+}
+
+// InferFilePatternMap reads the header of each file and suggests a FilePattern with InferFilePattern,
+// when the file delimiter detected by SniffDelimiter is not "," it is set in the FilePattern Dialect,
+// and the same for the encoding detected by DetectEncoding when it is not UTF-8.
+//
+// The archives are expanded and a FilePattern is suggested for each member.
+func InferFilePatternMap(files []string) (map[string]*FilePattern, error) {
+	patterns := make(map[string]*FilePattern)
+	for _, file := range files {
+		var readErr error
+		err := walkFile(file, func(input *inputFile) {
+			if readErr != nil {
+				return
+			}
+
+			header, delimiter, encodingName, err := readHeader(input.reader, input.name)
+			if err != nil {
+				readErr = &errs.FileError{Path: input.key, Op: errs.OpRead, Err: errs.NewError(errs.ErrReadingFile, err.Error())}
+				return
+			}
+
+			pattern := InferFilePattern(header)
+			if delimiter != ',' {
+				pattern.Dialect = &Dialect{Delimiter: string(delimiter)}
+			}
+			if encodingName != EncodingUTF8 {
+				pattern.Encoding = encodingName
+			}
+			patterns[input.key] = pattern
+		})
+		if err != nil {
+			return nil, err
+		}
+		if readErr != nil {
+			return nil, readErr
+		}
+	}
+
+	return patterns, nil
+}
+
+// readHeader reads the header of a file in the Format of its name, with the delimiter and encoding detected
+// when it is a CSV file.
+func readHeader(r io.Reader, name string) (header []string, delimiter rune, encodingName string, err error) {
+	switch format := inputFormat(name, nil); format {
+	case FormatJSON, FormatNDJSON:
+		// the JSON and XML columns are the paths of the first object fields.
+		header, encodingName, err = readJSONColumns(r, format)
+		return header, ',', encodingName, err
+	case FormatXML:
+		header, encodingName, err = readXMLColumns(r, "")
+		return header, ',', encodingName, err
+	case FormatXLSX:
+		// a workbook has no delimiter or encoding to be detected.
+		reader, err := newXLSXRecordReader(r, nil)
+		if err != nil {
+			return nil, 0, "", err
+		}
+		defer reader.Close()
+
+		header, _, err = reader.Read()
+		return header, ',', EncodingUTF8, err
+	}
+
+	decoded, encodingName, err := newDecoder(r, EncodingAuto)
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	reader, err := newReader(decoded, &Dialect{Delimiter: DelimiterAuto})
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	header, err = reader.Read()
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	return header, reader.Comma, encodingName, nil
+}
+
+// normalizeColumnName keeps only the lower case letters and digits of a column name, "E-mail" becomes "email".
+func normalizeColumnName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
 func validateFilePattern(filePatternMap map[string]*FilePattern) error {
 	if len(filePatternMap) == 0 {
 		return errs.ErrEmptyFilePatternMapReceived
 	}
 
 	for fileName, filePattern := range filePatternMap {
+		if isGlob(fileName) {
+			if _, err := path.Match(fileName, ""); err != nil {
+				return errs.NewError(errs.ErrInvalidGlob, fileName)
+			}
+		}
+
 		if filePattern.EmailColumn == "" || filePattern.IDColumn == "" || filePattern.SalaryColumn == "" ||
 			filePattern.FirstNameColumn == "" {
 			return errs.NewError(errs.ErrInvalidFilePattern, fileName)
 		}
+
+		if err := validateDialect(filePattern.Dialect); err != nil {
+			return fmt.Errorf("%w: %s", err, fileName)
+		}
+
+		if err := validateEncoding(filePattern.Encoding); err != nil {
+			return fmt.Errorf("%w: %s", err, fileName)
+		}
+
+		if err := validateInputFormat(filePattern.Format); err != nil {
+			return fmt.Errorf("%w: %s", err, fileName)
+		}
+
+		if err := validateSheet(filePattern.Sheet); err != nil {
+			return fmt.Errorf("%w: %s", err, fileName)
+		}
+
+		if err := validateAttributes(filePattern.Attributes); err != nil {
+			return fmt.Errorf("%w: %s", err, fileName)
+		}
+
+		if err := validateFixedWidth(filePattern.FixedWidth, filePattern); err != nil {
+			return fmt.Errorf("%w: %s", err, fileName)
+		}
 	}
 
 	return nil
@@ -0,0 +1,74 @@
+package csv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindPattern(t *testing.T) {
+	var (
+		exact    = &FilePattern{IDColumn: "exact"}
+		member   = &FilePattern{IDColumn: "member"}
+		anyCSV   = &FilePattern{IDColumn: "*.csv"}
+		rosters  = &FilePattern{IDColumn: "roster*.csv"}
+		dropPath = &FilePattern{IDColumn: "drop/*"}
+
+		givenPatterns = map[string]*FilePattern{
+			"drop/roster1.csv":    exact,
+			"payroll.csv":         member,
+			"*.csv":               anyCSV,
+			"roster*.csv":         rosters,
+			"drop/*":              dropPath,
+			"archive.zip!*/*.csv": anyCSV,
+		}
+	)
+
+	tt := []struct {
+		name       string
+		givenInput *inputFile
+		want       *FilePattern
+	}{
+		{
+			name:       "Exact key",
+			givenInput: &inputFile{key: "drop/roster1.csv"},
+			want:       exact,
+		},
+		{
+			name:       "Member path",
+			givenInput: &inputFile{key: "archive.zip!payroll.csv", member: "payroll.csv"},
+			want:       member,
+		},
+		{
+			name:       "File name",
+			givenInput: &inputFile{key: "inbox/payroll.csv"},
+			want:       member,
+		},
+		{
+			name:       "Longest glob by name",
+			givenInput: &inputFile{key: "in/roster2.csv"},
+			want:       rosters,
+		},
+		{
+			name:       "Glob by path before glob by name",
+			givenInput: &inputFile{key: "drop/roster2.csv"},
+			want:       dropPath,
+		},
+		{
+			name:       "Member name glob",
+			givenInput: &inputFile{key: "archive.zip!more/other.csv", member: "more/other.csv"},
+			want:       anyCSV,
+		},
+		{
+			name:       "Not found",
+			givenInput: &inputFile{key: "in/employees.xlsx"},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := findPattern(givenPatterns, tc.givenInput)
+			assert.Equal(t, tc.want != nil, ok)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/vsantosalmeida/csv-parser/pkg/errors"
 	"github.com/vsantosalmeida/csv-parser/usecase/csv"
 )
 
@@ -43,3 +44,140 @@ func TestNewFilePatternMap(t *testing.T) {
 	got := csv.NewFilePatternMap([]string{givenFile})
 	assert.Equal(t, want, got)
 }
+
+func TestLoadFilePatternMap(t *testing.T) {
+	want := map[string]*csv.FilePattern{
+		"test_files/roster1.csv": {
+			FirstNameColumn: "Name",
+			SalaryColumn:    "Wage",
+			EmailColumn:     "Email",
+			IDColumn:        "Number",
+		},
+		"test_files/roster3.csv": {
+			FirstNameColumn: "first name",
+			LastNameColumn:  "last name",
+			SalaryColumn:    "Rate",
+			EmailColumn:     "e-mail",
+			IDColumn:        "Employee Number",
+			PhoneColumn:     "Mobile",
+		},
+	}
+
+	got, err := csv.LoadFilePatternMap("test_files/patterns.json")
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestLoadFilePatternMap_Error(t *testing.T) {
+	tt := []struct {
+		name      string
+		givenPath string
+		wantErr   error
+	}{
+		{
+			name:      "File not found",
+			givenPath: "test_files/not_found.json",
+			wantErr:   errors.ErrOpeningFile,
+		},
+		{
+			name:      "Invalid JSON",
+			givenPath: "test_files/roster1.csv",
+			wantErr:   errors.ErrInvalidFilePatternConfig,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := csv.LoadFilePatternMap(tc.givenPath)
+			assert.Nil(t, got)
+			assert.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestInferFilePattern(t *testing.T) {
+	tt := []struct {
+		name        string
+		givenHeader []string
+		want        *csv.FilePattern
+	}{
+		{
+			name:        "Full name column",
+			givenHeader: []string{"Name", "Email", "Wage", "Number"},
+			want: &csv.FilePattern{
+				FirstNameColumn: "Name",
+				SalaryColumn:    "Wage",
+				EmailColumn:     "Email",
+				IDColumn:        "Number",
+			},
+		},
+		{
+			name:        "Abbreviated columns",
+			givenHeader: []string{"f. name", "l. name", "email", "wage", "emp id", "phone"},
+			want: &csv.FilePattern{
+				FirstNameColumn: "f. name",
+				LastNameColumn:  "l. name",
+				SalaryColumn:    "wage",
+				EmailColumn:     "email",
+				IDColumn:        "emp id",
+				PhoneColumn:     "phone",
+			},
+		},
+		{
+			name:        "First name has priority over name",
+			givenHeader: []string{"Name", "First Name", "Surname", "E-mail", "Employee Number", "Rate", "Mobile"},
+			want: &csv.FilePattern{
+				FirstNameColumn: "First Name",
+				LastNameColumn:  "Surname",
+				SalaryColumn:    "Rate",
+				EmailColumn:     "E-mail",
+				IDColumn:        "Employee Number",
+				PhoneColumn:     "Mobile",
+			},
+		},
+		{
+			name:        "Unknown columns",
+			givenHeader: []string{"foo", "bar"},
+			want:        &csv.FilePattern{},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, csv.InferFilePattern(tc.givenHeader))
+		})
+	}
+}
+
+func TestInferFilePatternMap(t *testing.T) {
+	want := map[string]*csv.FilePattern{
+		"test_files/roster2.csv": {
+			FirstNameColumn: "First",
+			LastNameColumn:  "Last",
+			SalaryColumn:    "Salary",
+			EmailColumn:     "E-mail",
+			IDColumn:        "ID",
+		},
+	}
+
+	got, err := csv.InferFilePatternMap([]string{"test_files/roster2.csv"})
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	got, err = csv.InferFilePatternMap([]string{"test_files/roster7.tsv"})
+	assert.NoError(t, err)
+	assert.Equal(t, &csv.Dialect{Delimiter: "\t"}, got["test_files/roster7.tsv"].Dialect)
+
+	got, err = csv.InferFilePatternMap([]string{"test_files/roster19.tar.zst"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]*csv.FilePattern{
+		"test_files/roster19.tar.zst!a.csv": {
+			FirstNameColumn: "Name",
+			SalaryColumn:    "Wage",
+			EmailColumn:     "Email",
+			IDColumn:        "Number",
+		},
+	}, got)
+
+	got, err = csv.InferFilePatternMap([]string{"not_found.csv"})
+	assert.Nil(t, got)
+	assert.ErrorIs(t, err, errors.ErrOpeningFile)
+}
@@ -0,0 +1,202 @@
+package csv
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	errs "github.com/vsantosalmeida/csv-parser/pkg/errors"
+)
+
+// FormatFixedWidth is the Format of the text files where each field has a fixed position in the line,
+// only supported as input.
+const FormatFixedWidth Format = "fixed"
+
+// Trim modes of the fixed-width fields.
+const (
+	TrimBoth  = "both"
+	TrimLeft  = "left"
+	TrimRight = "right"
+	TrimNone  = "none"
+)
+
+// FixedWidth configures how a FormatFixedWidth file is read, the FilePattern columns are the Columns names.
+type FixedWidth struct {
+	Columns []*FixedWidthColumn `json:"columns"`
+	// Trim removes the white spaces around the fields, TrimBoth when empty.
+	Trim string `json:"trim,omitempty"`
+	// SkipLines is the number of lines at the beginning of the file to ignore, like a header or a banner.
+	SkipLines int `json:"skip_lines,omitempty"`
+	// AllowShortLines reads the missing chars of a line shorter than the Columns as empty,
+	// otherwise the line is a bad data.
+	AllowShortLines bool `json:"allow_short_lines,omitempty"`
+}
+
+// FixedWidthColumn is a named field of a fixed-width line.
+type FixedWidthColumn struct {
+	Name string `json:"name"`
+	// Start is the position of the first char of the field, the first char of the line is 1.
+	Start int `json:"start"`
+	// Length is the number of chars of the field.
+	Length int `json:"length"`
+	// Trim overrides the FixedWidth Trim for the column.
+	Trim string `json:"trim,omitempty"`
+}
+
+func validateFixedWidth(f *FixedWidth, pattern *FilePattern) error {
+	if f == nil {
+		if pattern.Format == FormatFixedWidth {
+			return errs.NewError(errs.ErrInvalidFixedWidth, "the columns are required")
+		}
+		return nil
+	}
+
+	if len(f.Columns) == 0 {
+		return errs.NewError(errs.ErrInvalidFixedWidth, "the columns are required")
+	}
+
+	if f.SkipLines < 0 {
+		return errs.NewError(errs.ErrInvalidFixedWidth, "the skip lines must not be negative")
+	}
+
+	if !validTrim(f.Trim) {
+		return errs.NewError(errs.ErrInvalidFixedWidth, fmt.Sprintf("unknown trim %q", f.Trim))
+	}
+
+	names := make(map[string]bool, len(f.Columns))
+	for _, column := range f.Columns {
+		switch {
+		case column.Name == "":
+			return errs.NewError(errs.ErrInvalidFixedWidth, "the column name is required")
+		case names[column.Name]:
+			return errs.NewError(errs.ErrInvalidFixedWidth, fmt.Sprintf("the column %q is repeated", column.Name))
+		case column.Start < 1 || column.Length < 1:
+			return errs.NewError(errs.ErrInvalidFixedWidth, fmt.Sprintf("the column %q start and length must be greater than 0", column.Name))
+		case !validTrim(column.Trim):
+			return errs.NewError(errs.ErrInvalidFixedWidth, fmt.Sprintf("unknown trim %q of the column %q", column.Trim, column.Name))
+		}
+		names[column.Name] = true
+	}
+
+	for _, column := range pattern.columns() {
+		if !names[column] {
+			return errs.NewError(errs.ErrInvalidFixedWidth, fmt.Sprintf("the column %q is not defined", column))
+		}
+	}
+
+	return nil
+}
+
+func validTrim(trim string) bool {
+	switch trim {
+	case "", TrimBoth, TrimLeft, TrimRight, TrimNone:
+		return true
+	default:
+		return false
+	}
+}
+
+// fixedWidthRecordReader is a recordReader for a FormatFixedWidth file, the header is made by the columns names.
+type fixedWidthRecordReader struct {
+	reader *bufio.Reader
+	config *FixedWidth
+	// width is the number of chars needed to read all the columns.
+	width  int
+	line   int
+	header bool
+}
+
+// newFixedWidthRecordReader transcodes r to UTF-8 and reads it following the FixedWidth config.
+func newFixedWidthRecordReader(r io.Reader, config *FixedWidth, encodingName string) (*fixedWidthRecordReader, error) {
+	if config == nil {
+		return nil, errs.NewError(errs.ErrInvalidFixedWidth, "the columns are required")
+	}
+
+	decoded, _, err := newDecoder(r, encodingName)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := &fixedWidthRecordReader{
+		reader: bufio.NewReader(decoded),
+		config: config,
+	}
+	for _, column := range config.Columns {
+		if end := column.Start + column.Length - 1; end > reader.width {
+			reader.width = end
+		}
+	}
+
+	return reader, nil
+}
+
+func (r *fixedWidthRecordReader) Read() ([]string, int, error) {
+	if !r.header {
+		r.header = true
+		header := make([]string, 0, len(r.config.Columns))
+		for _, column := range r.config.Columns {
+			header = append(header, column.Name)
+		}
+		return header, 1, nil
+	}
+
+	for {
+		text, err := r.reader.ReadString('\n')
+		if err != nil && (err != io.EOF || text == "") {
+			return nil, 0, err
+		}
+		r.line++
+
+		text = strings.TrimRight(text, "\r\n")
+		if r.line <= r.config.SkipLines || strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		chars := []rune(text)
+		if len(chars) < r.width && !r.config.AllowShortLines {
+			return nil, r.line, &errs.ValidationError{
+				Field: errs.FieldRecord,
+				Line:  r.line,
+				Value: text,
+				Code:  errs.NewError(errs.ErrShortLine, fmt.Sprintf("%d of %d chars", len(chars), r.width)),
+			}
+		}
+
+		record := make([]string, 0, len(r.config.Columns))
+		for _, column := range r.config.Columns {
+			record = append(record, r.field(chars, column))
+		}
+
+		return record, r.line, nil
+	}
+}
+
+func (r *fixedWidthRecordReader) field(chars []rune, column *FixedWidthColumn) string {
+	start := column.Start - 1
+	if start >= len(chars) {
+		return ""
+	}
+
+	end := start + column.Length
+	if end > len(chars) {
+		end = len(chars)
+	}
+	value := string(chars[start:end])
+
+	trim := column.Trim
+	if trim == "" {
+		trim = r.config.Trim
+	}
+	switch trim {
+	case TrimNone:
+		return value
+	case TrimLeft:
+		return strings.TrimLeftFunc(value, unicode.IsSpace)
+	case TrimRight:
+		return strings.TrimRightFunc(value, unicode.IsSpace)
+	default:
+		return strings.TrimSpace(value)
+	}
+}
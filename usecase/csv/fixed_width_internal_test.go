@@ -0,0 +1,40 @@
+package csv
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixedWidthRecordReader_Read(t *testing.T) {
+	givenConfig := &FixedWidth{
+		Columns: []*FixedWidthColumn{
+			{Name: "id", Start: 1, Length: 4, Trim: TrimLeft},
+			{Name: "name", Start: 5, Length: 6, Trim: TrimRight},
+			{Name: "phone", Start: 11, Length: 8},
+		},
+		Trim:            TrimNone,
+		AllowShortLines: true,
+	}
+
+	reader, err := newFixedWidthRecordReader(strings.NewReader("  1 John  555-0100\n  2 Mary\n"), givenConfig, EncodingUTF8)
+	assert.NoError(t, err)
+
+	var gotRecords [][]string
+	for {
+		record, _, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		gotRecords = append(gotRecords, record)
+	}
+
+	assert.Equal(t, [][]string{
+		{"id", "name", "phone"},
+		{"1 ", "John", "555-0100"},
+		{"2 ", "Mary", ""},
+	}, gotRecords)
+}
@@ -0,0 +1,281 @@
+package csv
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vsantosalmeida/csv-parser/entity"
+	errs "github.com/vsantosalmeida/csv-parser/pkg/errors"
+)
+
+// Format is the encoding of an input or result file.
+type Format string
+
+const (
+	FormatJSON   Format = "json"
+	FormatNDJSON Format = "ndjson"
+	FormatCSV    Format = "csv"
+	FormatXML    Format = "xml"
+)
+
+// FormatFromPath returns the Format of a file from its extension.
+func FormatFromPath(path string) (Format, error) {
+	switch f := Format(strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")); f {
+	case FormatJSON, FormatNDJSON, FormatCSV, FormatXML:
+		return f, nil
+	default:
+		return "", errs.NewError(errs.ErrUnsupportedFormat, path)
+	}
+}
+
+// badDataLine is a BadData with its file, used by the formats without a map structure.
+type badDataLine struct {
+	File    string      `json:"file" xml:"file,attr"`
+	Line    json.Number `json:"line" xml:"line,attr"`
+	Reasons []string    `json:"reasons" xml:"reason"`
+}
+
+type employeesXML struct {
+	XMLName   xml.Name           `xml:"employees"`
+	Employees []*entity.Employee `xml:"employee"`
+}
+
+type badDataXML struct {
+	XMLName xml.Name       `xml:"badData"`
+	Lines   []*badDataLine `xml:"line"`
+}
+
+var (
+	employeesCSVHeader = []string{"id", "email", "name", "salary", "phone"}
+	badDataCSVHeader   = []string{"file", "line", "reasons"}
+)
+
+// EncodeEmployees writes the employees to w in the given Format.
+func EncodeEmployees(w io.Writer, format Format, employees []*entity.Employee) error {
+	switch format {
+	case FormatJSON:
+		return encodeIndentedJSON(w, employees)
+	case FormatNDJSON:
+		enc := json.NewEncoder(w)
+		for _, employee := range employees {
+			if err := enc.Encode(employee); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatCSV:
+		// each custom attribute is a column after the employee fields, empty for the employees without it.
+		attributes := attributeNames(employees)
+		cw := csv.NewWriter(w)
+		if err := cw.Write(append(append([]string{}, employeesCSVHeader...), attributes...)); err != nil {
+			return err
+		}
+		for _, e := range employees {
+			record := []string{e.ID, e.Email, e.Name, strconv.FormatFloat(e.Salary, 'f', -1, 64), e.Phone}
+			for _, name := range attributes {
+				record = append(record, entity.FormatAttribute(e.Attributes[name]))
+			}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case FormatXML:
+		return encodeIndentedXML(w, employeesXML{Employees: employees})
+	default:
+		return errs.NewError(errs.ErrUnsupportedFormat, string(format))
+	}
+}
+
+// attributeNames returns the names of the custom attributes of all the employees, sorted.
+func attributeNames(employees []*entity.Employee) []string {
+	all := make(entity.Attributes)
+	for _, e := range employees {
+		for name := range e.Attributes {
+			all[name] = nil
+		}
+	}
+
+	return all.Names()
+}
+
+// EncodeBadData writes the bad data to w in the given Format, the files are sorted by name.
+func EncodeBadData(w io.Writer, format Format, badData map[string][]*BadData) error {
+	if format == FormatJSON {
+		return encodeIndentedJSON(w, badData)
+	}
+
+	lines := flattenBadData(badData)
+	switch format {
+	case FormatNDJSON:
+		enc := json.NewEncoder(w)
+		for _, line := range lines {
+			if err := enc.Encode(line); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(badDataCSVHeader); err != nil {
+			return err
+		}
+		for _, line := range lines {
+			if err := cw.Write([]string{line.File, line.Line.String(), strings.Join(line.Reasons, "; ")}); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case FormatXML:
+		return encodeIndentedXML(w, badDataXML{Lines: lines})
+	default:
+		return errs.NewError(errs.ErrUnsupportedFormat, string(format))
+	}
+}
+
+// ConvertResultFile reads an employees or bad data result file written in JSON or NDJSON,
+// and writes it again to out, the formats are taken from the files' extensions.
+func ConvertResultFile(in, out string) error {
+	inFormat, err := FormatFromPath(in)
+	if err != nil {
+		return err
+	}
+
+	if inFormat != FormatJSON && inFormat != FormatNDJSON {
+		return errs.NewError(errs.ErrUnsupportedFormat, in)
+	}
+
+	outFormat, err := FormatFromPath(out)
+	if err != nil {
+		return err
+	}
+
+	b, err := ioutil.ReadFile(in)
+	if err != nil {
+		return &errs.FileError{Path: in, Op: errs.OpOpen, Err: errs.NewError(errs.ErrOpeningFile, err.Error())}
+	}
+
+	employees, badData, err := decodeResultFile(inFormat, b)
+	if err != nil {
+		return &errs.FileError{Path: in, Op: errs.OpRead, Err: errs.NewError(errs.ErrReadingFile, err.Error())}
+	}
+
+	var buf bytes.Buffer
+	if badData != nil {
+		err = EncodeBadData(&buf, outFormat, badData)
+	} else {
+		err = EncodeEmployees(&buf, outFormat, employees)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(out, buf.Bytes(), 0644); err != nil {
+		return &errs.FileError{Path: out, Op: errs.OpWrite, Err: errs.NewError(errs.ErrWriteFile, err.Error())}
+	}
+
+	return nil
+}
+
+// decodeResultFile decodes an employees result file, a JSON array or NDJSON objects,
+// or a bad data result file, a JSON object by file name or NDJSON objects with the "file" key.
+func decodeResultFile(format Format, b []byte) (employees []*entity.Employee, badData map[string][]*BadData, err error) {
+	switch format {
+	case FormatJSON:
+		if trimmed := bytes.TrimSpace(b); len(trimmed) != 0 && trimmed[0] == '{' {
+			err = json.Unmarshal(b, &badData)
+			return
+		}
+		err = json.Unmarshal(b, &employees)
+		return
+	case FormatNDJSON:
+		scanner := bufio.NewScanner(bytes.NewReader(b))
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+
+			var object map[string]json.RawMessage
+			if err = json.Unmarshal(line, &object); err != nil {
+				return nil, nil, err
+			}
+
+			if _, ok := object["file"]; ok {
+				var bd badDataLine
+				if err = json.Unmarshal(line, &bd); err != nil {
+					return nil, nil, err
+				}
+				if badData == nil {
+					badData = make(map[string][]*BadData)
+				}
+				badData[bd.File] = append(badData[bd.File], &BadData{Line: bd.Line, Reasons: bd.Reasons})
+				continue
+			}
+
+			var employee entity.Employee
+			if err = json.Unmarshal(line, &employee); err != nil {
+				return nil, nil, err
+			}
+			employees = append(employees, &employee)
+		}
+		err = scanner.Err()
+		return
+	default:
+		return nil, nil, errs.NewError(errs.ErrUnsupportedFormat, string(format))
+	}
+}
+
+func flattenBadData(badData map[string][]*BadData) []*badDataLine {
+	files := make([]string, 0, len(badData))
+	for file := range badData {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	lines := make([]*badDataLine, 0, len(badData))
+	for _, file := range files {
+		for _, bd := range badData[file] {
+			lines = append(lines, &badDataLine{File: file, Line: bd.Line, Reasons: bd.Reasons})
+		}
+	}
+
+	return lines
+}
+
+func encodeIndentedJSON(w io.Writer, v interface{}) error {
+	b, err := json.MarshalIndent(v, "", " ")
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(b)
+	return err
+}
+
+func encodeIndentedXML(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", " ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,234 @@
+package csv_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vsantosalmeida/csv-parser/entity"
+	"github.com/vsantosalmeida/csv-parser/pkg/errors"
+	"github.com/vsantosalmeida/csv-parser/usecase/csv"
+)
+
+var (
+	formatEmployees = []*entity.Employee{
+		{
+			ID:     "RT1",
+			Email:  "doe@test.com",
+			Name:   "John Doe",
+			Salary: 10,
+		},
+		{
+			ID:     "RT2",
+			Email:  "mary@tes.com",
+			Name:   "Mary Jane",
+			Salary: 15.5,
+			Phone:  "144 856 1274",
+			Attributes: entity.Attributes{
+				"department": "Sales",
+				"full_time":  true,
+				"hired":      "2020-01-15",
+				"level":      float64(3),
+			},
+		},
+	}
+	formatBadData = map[string][]*csv.BadData{
+		"roster2.csv": {
+			{
+				Line:    "3",
+				Reasons: []string{errors.ErrInvalidIDValue.Error()},
+			},
+		},
+		"roster1.csv": {
+			{
+				Line:    "5",
+				Reasons: []string{errors.ErrEmptyName.Error(), errors.ErrInvalidEmailFormat.Error()},
+			},
+		},
+	}
+)
+
+func TestFormatFromPath(t *testing.T) {
+	got, err := csv.FormatFromPath("dir/employees.NDJSON")
+	assert.NoError(t, err)
+	assert.Equal(t, csv.FormatNDJSON, got)
+
+	got, err = csv.FormatFromPath("employees.txt")
+	assert.Empty(t, got)
+	assert.ErrorIs(t, err, errors.ErrUnsupportedFormat)
+}
+
+func TestEncodeEmployees(t *testing.T) {
+	tt := []struct {
+		name        string
+		givenFormat csv.Format
+		want        string
+	}{
+		{
+			name:        "NDJSON",
+			givenFormat: csv.FormatNDJSON,
+			want: `{"id":"RT1","email":"doe@test.com","name":"John Doe","salary":10}
+{"id":"RT2","email":"mary@tes.com","name":"Mary Jane","salary":15.5,"phone":"144 856 1274","attributes":{"department":"Sales","full_time":true,"hired":"2020-01-15","level":3}}
+`,
+		},
+		{
+			name:        "CSV",
+			givenFormat: csv.FormatCSV,
+			want: `id,email,name,salary,phone,department,full_time,hired,level
+RT1,doe@test.com,John Doe,10,,,,,
+RT2,mary@tes.com,Mary Jane,15.5,144 856 1274,Sales,true,2020-01-15,3
+`,
+		},
+		{
+			name:        "XML",
+			givenFormat: csv.FormatXML,
+			want: `<?xml version="1.0" encoding="UTF-8"?>
+<employees>
+ <employee>
+  <id>RT1</id>
+  <email>doe@test.com</email>
+  <name>John Doe</name>
+  <salary>10</salary>
+ </employee>
+ <employee>
+  <id>RT2</id>
+  <email>mary@tes.com</email>
+  <name>Mary Jane</name>
+  <salary>15.5</salary>
+  <phone>144 856 1274</phone>
+  <attributes>
+   <attribute name="department">Sales</attribute>
+   <attribute name="full_time">true</attribute>
+   <attribute name="hired">2020-01-15</attribute>
+   <attribute name="level">3</attribute>
+  </attributes>
+ </employee>
+</employees>
+`,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := csv.EncodeEmployees(&buf, tc.givenFormat, formatEmployees)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, buf.String())
+		})
+	}
+}
+
+func TestEncodeBadData(t *testing.T) {
+	tt := []struct {
+		name        string
+		givenFormat csv.Format
+		want        string
+	}{
+		{
+			name:        "NDJSON",
+			givenFormat: csv.FormatNDJSON,
+			want: `{"file":"roster1.csv","line":5,"reasons":["a name is required","e-mail must be a valid address ex: email@example.com"]}
+{"file":"roster2.csv","line":3,"reasons":["an id is required"]}
+`,
+		},
+		{
+			name:        "CSV",
+			givenFormat: csv.FormatCSV,
+			want: `file,line,reasons
+roster1.csv,5,a name is required; e-mail must be a valid address ex: email@example.com
+roster2.csv,3,an id is required
+`,
+		},
+		{
+			name:        "XML",
+			givenFormat: csv.FormatXML,
+			want: `<?xml version="1.0" encoding="UTF-8"?>
+<badData>
+ <line file="roster1.csv" line="5">
+  <reason>a name is required</reason>
+  <reason>e-mail must be a valid address ex: email@example.com</reason>
+ </line>
+ <line file="roster2.csv" line="3">
+  <reason>an id is required</reason>
+ </line>
+</badData>
+`,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := csv.EncodeBadData(&buf, tc.givenFormat, formatBadData)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, buf.String())
+		})
+	}
+}
+
+func TestConvertResultFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "convert")
+	if err != nil {
+		t.Fatalf("create temp dir error: %q", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		employeesJSON  = filepath.Join(dir, "employee.json")
+		employeeNDJSON = filepath.Join(dir, "employee.ndjson")
+		employeeBack   = filepath.Join(dir, "employee-back.json")
+		badDataJSON    = filepath.Join(dir, "badData.json")
+		badDataNDJSON  = filepath.Join(dir, "badData.ndjson")
+		badDataBack    = filepath.Join(dir, "badData-back.json")
+	)
+
+	var buf bytes.Buffer
+	assert.NoError(t, csv.EncodeEmployees(&buf, csv.FormatJSON, formatEmployees))
+	assert.NoError(t, ioutil.WriteFile(employeesJSON, buf.Bytes(), 0644))
+	buf.Reset()
+	assert.NoError(t, csv.EncodeBadData(&buf, csv.FormatJSON, formatBadData))
+	assert.NoError(t, ioutil.WriteFile(badDataJSON, buf.Bytes(), 0644))
+
+	// converting to NDJSON and back to JSON must keep the same content.
+	assert.NoError(t, csv.ConvertResultFile(employeesJSON, employeeNDJSON))
+	assert.NoError(t, csv.ConvertResultFile(employeeNDJSON, employeeBack))
+	assert.Equal(t, loadFile(employeesJSON, t), loadFile(employeeBack, t))
+
+	assert.NoError(t, csv.ConvertResultFile(badDataJSON, badDataNDJSON))
+	assert.NoError(t, csv.ConvertResultFile(badDataNDJSON, badDataBack))
+	assert.Equal(t, loadFile(badDataJSON, t), loadFile(badDataBack, t))
+}
+
+func TestConvertResultFile_Error(t *testing.T) {
+	tt := []struct {
+		name     string
+		givenIn  string
+		givenOut string
+		wantErr  error
+	}{
+		{
+			name:     "Unsupported input format",
+			givenIn:  "employee.xml",
+			givenOut: "employee.csv",
+			wantErr:  errors.ErrUnsupportedFormat,
+		},
+		{
+			name:     "Unsupported output format",
+			givenIn:  "employee.json",
+			givenOut: "employee.txt",
+			wantErr:  errors.ErrUnsupportedFormat,
+		},
+		{
+			name:     "Input not found",
+			givenIn:  "not_found.json",
+			givenOut: "employee.csv",
+			wantErr:  errors.ErrOpeningFile,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.ErrorIs(t, csv.ConvertResultFile(tc.givenIn, tc.givenOut), tc.wantErr)
+		})
+	}
+}
@@ -15,11 +15,21 @@ type Parser interface {
 	// In case of error to process a file will add the error to map[string]error with the file name as the key
 	// and the received error as a value.
 	ParseFiles(files []string) (errors map[string]error)
+
+	// Validate is a dry-run of ParseFiles, each CSV file from the []string is fully processed
+	// but no result file is written.
+	//
+	// Returns a Report with the bad data of each file and the errors map like ParseFiles.
+	Validate(files []string) (report *Report, errors map[string]error)
+
+	// Summary returns the counters and the result files of the last ParseFiles or Validate call,
+	// nil if none of them was called yet. The Summary of Validate has no result files.
+	Summary() *Summary
 }
 
 // Writer is an embedded interface in Parser, responsible to write files with the results from the
 // Parser.ParseFiles method.
 type Writer interface {
-	writeEmployeesResultFile(employees []*entity.Employee) error
-	writeBadDataResultFile(badData map[string][]*BadData) error
+	writeEmployeesResultFile(employees []*entity.Employee) (fileName string, err error)
+	writeBadDataResultFile(badData map[string][]*BadData) (fileName string, err error)
 }
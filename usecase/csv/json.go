@@ -0,0 +1,271 @@
+package csv
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	errs "github.com/vsantosalmeida/csv-parser/pkg/errors"
+)
+
+// jsonRecordReader is a recordReader for a JSON array or NDJSON file of objects.
+//
+// The header is made by the FilePattern columns, which are the object keys or dotted paths to the nested fields,
+// like "contact.email", and each object is a record with the values of the columns.
+type jsonRecordReader struct {
+	columns []string
+	header  bool
+	// encoding is the name of the file encoding, detected when the FilePattern has none.
+	encoding string
+	// next returns the next object of the file with its line.
+	next func() (object interface{}, line int, err error)
+}
+
+// newJSONRecordReader transcodes r to UTF-8 and reads it as a FormatJSON array or a FormatNDJSON file.
+func newJSONRecordReader(r io.Reader, format Format, pattern *FilePattern) (*jsonRecordReader, error) {
+	decoded, encodingName, err := newDecoder(r, pattern.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := &jsonRecordReader{columns: pattern.columns(), encoding: encodingName}
+	counter := &lineCounter{reader: decoded}
+	if format == FormatNDJSON {
+		reader.next = ndjsonObjects(bufio.NewReader(counter))
+		return reader, nil
+	}
+
+	decoder := json.NewDecoder(counter)
+	decoder.UseNumber()
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return nil, fmt.Errorf("a JSON array was expected, found %v", token)
+	}
+	reader.next = jsonArrayObjects(decoder, counter)
+
+	return reader, nil
+}
+
+func (r *jsonRecordReader) Read() ([]string, int, error) {
+	if !r.header {
+		r.header = true
+		return r.columns, 1, nil
+	}
+
+	object, line, err := r.next()
+	if err != nil {
+		return nil, line, err
+	}
+
+	fields, ok := object.(map[string]interface{})
+	if !ok {
+		return nil, line, &errs.ValidationError{
+			Field: errs.FieldRecord,
+			Line:  line,
+			Code:  errs.NewError(errs.ErrMalformedLine, "a JSON object was expected"),
+		}
+	}
+
+	record := make([]string, 0, len(r.columns))
+	for _, column := range r.columns {
+		record = append(record, jsonText(lookupPath(fields, column)))
+	}
+
+	return record, line, nil
+}
+
+// jsonArrayObjects returns the values of a JSON array one by one, the decoder must be after the array start.
+// A syntax error can not be recovered, so it stops the file.
+func jsonArrayObjects(decoder *json.Decoder, counter *lineCounter) func() (interface{}, int, error) {
+	return func() (interface{}, int, error) {
+		if !decoder.More() {
+			if _, err := decoder.Token(); err != nil {
+				return nil, 0, err
+			}
+			return nil, 0, io.EOF
+		}
+
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, 0, err
+		}
+		line := counter.line(decoder.InputOffset() - int64(len(raw)))
+
+		var object interface{}
+		d := json.NewDecoder(bytes.NewReader(raw))
+		d.UseNumber()
+		if err := d.Decode(&object); err != nil {
+			return nil, line, err
+		}
+
+		return object, line, nil
+	}
+}
+
+// ndjsonObjects returns the value of each line of a NDJSON file, ignoring the blank lines.
+// A line with a syntax error is returned as a malformed line.
+func ndjsonObjects(reader *bufio.Reader) func() (interface{}, int, error) {
+	line := 0
+	return func() (interface{}, int, error) {
+		for {
+			text, err := reader.ReadString('\n')
+			if err != nil && (err != io.EOF || text == "") {
+				return nil, 0, err
+			}
+			line++
+
+			if strings.TrimSpace(text) == "" {
+				continue
+			}
+
+			var object interface{}
+			d := json.NewDecoder(strings.NewReader(text))
+			d.UseNumber()
+			if err := d.Decode(&object); err != nil {
+				return nil, line, &errs.ValidationError{
+					Field: errs.FieldRecord,
+					Line:  line,
+					Code:  errs.NewError(errs.ErrMalformedLine, err.Error()),
+				}
+			}
+
+			return object, line, nil
+		}
+	}
+}
+
+// lookupPath returns the value of a key, or of a dotted path like "contact.emails.0" to a nested object field
+// or array item, nil when it is not found. A key with dots is also found, like "e.mail".
+func lookupPath(value interface{}, path string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if field, ok := v[path]; ok {
+			return field
+		}
+		for i := strings.Index(path, "."); i >= 0; i = nextDot(path, i) {
+			if field, ok := v[path[:i]]; ok {
+				if found := lookupPath(field, path[i+1:]); found != nil {
+					return found
+				}
+			}
+		}
+	case []interface{}:
+		head, tail := path, ""
+		if i := strings.Index(path, "."); i >= 0 {
+			head, tail = path[:i], path[i+1:]
+		}
+		index, err := strconv.Atoi(head)
+		if err != nil || index < 0 || index >= len(v) {
+			return nil
+		}
+		if tail == "" {
+			return v[index]
+		}
+		return lookupPath(v[index], tail)
+	}
+
+	return nil
+}
+
+func nextDot(path string, i int) int {
+	j := strings.Index(path[i+1:], ".")
+	if j < 0 {
+		return -1
+	}
+
+	return i + 1 + j
+}
+
+// jsonText returns a JSON value as a field, the objects and arrays are written as JSON.
+func jsonText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
+
+// readJSONColumns returns the columns of the first object of a JSON file, with the detected encoding.
+func readJSONColumns(r io.Reader, format Format) ([]string, string, error) {
+	reader, err := newJSONRecordReader(r, format, &FilePattern{})
+	if err != nil {
+		return nil, "", err
+	}
+
+	object, _, err := reader.next()
+	if err != nil {
+		return nil, "", err
+	}
+
+	fields, ok := object.(map[string]interface{})
+	if !ok {
+		return nil, "", fmt.Errorf("a JSON object was expected")
+	}
+
+	return jsonColumns(fields), reader.encoding, nil
+}
+
+// jsonColumns returns the dotted paths of the scalar fields of a JSON object, sorted by name.
+func jsonColumns(object map[string]interface{}) []string {
+	var columns []string
+	for key, value := range object {
+		if nested, ok := value.(map[string]interface{}); ok {
+			for _, column := range jsonColumns(nested) {
+				columns = append(columns, key+"."+column)
+			}
+			continue
+		}
+		columns = append(columns, key)
+	}
+	sort.Strings(columns)
+
+	return columns
+}
+
+// lineCounter counts the lines of the bytes read from reader, to find the line of an offset.
+type lineCounter struct {
+	reader io.Reader
+	// newlines holds the offsets of the new lines not yet passed by line.
+	newlines []int64
+	offset   int64
+	lines    int
+}
+
+func (c *lineCounter) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	for i, b := range p[:n] {
+		if b == '\n' {
+			c.newlines = append(c.newlines, c.offset+int64(i))
+		}
+	}
+	c.offset += int64(n)
+
+	return n, err
+}
+
+// line returns the line number of the offset, the offsets must be given in increasing order.
+func (c *lineCounter) line(offset int64) int {
+	i := 0
+	for ; i < len(c.newlines) && c.newlines[i] < offset; i++ {
+		c.lines++
+	}
+	c.newlines = c.newlines[i:]
+
+	return c.lines + 1
+}
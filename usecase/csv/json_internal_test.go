@@ -0,0 +1,46 @@
+package csv
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookupPath(t *testing.T) {
+	var givenObject map[string]interface{}
+	d := json.NewDecoder(strings.NewReader(`{
+		"id": 7,
+		"e.mail": "doe@test.com",
+		"name": {"first": "John", "last": null},
+		"phones": [{"number": "555-0100"}],
+		"active": true
+	}`))
+	d.UseNumber()
+	if err := d.Decode(&givenObject); err != nil {
+		t.Fatal(err)
+	}
+
+	tt := map[string]string{
+		"id":              "7",
+		"e.mail":          "doe@test.com",
+		"name.first":      "John",
+		"name.last":       "",
+		"name.middle":     "",
+		"phones.0.number": "555-0100",
+		"phones.1.number": "",
+		"active":          "true",
+		"name":            `{"first":"John","last":null}`,
+	}
+	for path, want := range tt {
+		assert.Equal(t, want, jsonText(lookupPath(givenObject, path)), path)
+	}
+}
+
+func TestReadJSONColumns(t *testing.T) {
+	columns, encodingName, err := readJSONColumns(strings.NewReader(`{"id": 1, "name": {"first": "John", "last": "Doe"}}`+"\n"), FormatNDJSON)
+	assert.NoError(t, err)
+	assert.Equal(t, EncodingUTF8, encodingName)
+	assert.Equal(t, []string{"id", "name.first", "name.last"}, columns)
+}
@@ -0,0 +1,202 @@
+package csv
+
+import (
+	"encoding/json"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vsantosalmeida/csv-parser/entity"
+	errs "github.com/vsantosalmeida/csv-parser/pkg/errors"
+)
+
+const writeLineageFile = "writeLineageFile"
+
+// MergePrecedence is how the employees found with the same ID in several files are merged by WithMerge.
+type MergePrecedence string
+
+const (
+	// MergeFileOrder keeps the whole employee of the first file, in the run order, with the ID.
+	MergeFileOrder MergePrecedence = "file_order"
+	// MergeNonEmpty takes each field from the first file, in the run order, with a non-empty value.
+	MergeNonEmpty MergePrecedence = "non_empty"
+	// MergeMostRecent takes each field from the most recently modified file with a non-empty value,
+	// the modification time of an archive member is the one stored in the archive.
+	MergeMostRecent MergePrecedence = "most_recent"
+)
+
+// Lineage holds the files supplying the fields of a merged employee, the fields are named like their JSON keys
+// and the custom attributes like "attributes.department".
+type Lineage struct {
+	ID string `json:"id"`
+	// Files holds the files with the ID, in the run order.
+	Files  []string          `json:"files"`
+	Fields map[string]string `json:"fields"`
+}
+
+// WithMerge merges the employees with the same ID found in several files into one employee by the precedence,
+// instead of rejecting the duplicated IDs, and writes the Lineage of each employee to a lineage result file.
+//
+// An ID is still unique in each file, and an e-mail can only be repeated by the same ID.
+func WithMerge(precedence MergePrecedence) Option {
+	return func(s *service) {
+		s.merge = precedence
+	}
+}
+
+func validateMerge(precedence MergePrecedence) error {
+	switch precedence {
+	case "", MergeFileOrder, MergeNonEmpty, MergeMostRecent:
+		return nil
+	default:
+		return errs.NewError(errs.ErrInvalidMerge, string(precedence))
+	}
+}
+
+// mergeSource is an employee found in a file, one of the sources of a merged employee.
+type mergeSource struct {
+	file     string
+	employee *entity.Employee
+}
+
+// mergeEmployees merges the employees of the accepted files by ID, in the order each ID was first found,
+// and replaces the employees of each file by the merged employees whose ID was first found in it.
+func mergeEmployees(precedence MergePrecedence, result *runResult) ([]*entity.Employee, []*Lineage) {
+	files := make([]string, 0, len(result.fileEmployees))
+	for _, file := range result.files {
+		if _, ok := result.fileEmployees[file]; ok {
+			files = append(files, file)
+		}
+	}
+
+	var (
+		ids     []string
+		sources = make(map[string][]*mergeSource)
+	)
+	for _, file := range files {
+		for _, employee := range result.fileEmployees[file] {
+			if _, ok := sources[employee.ID]; !ok {
+				ids = append(ids, employee.ID)
+			}
+			sources[employee.ID] = append(sources[employee.ID], &mergeSource{file: file, employee: employee})
+		}
+	}
+
+	employees := make([]*entity.Employee, 0, len(ids))
+	lineage := make([]*Lineage, 0, len(ids))
+	merged := make(map[string][]*entity.Employee, len(files))
+	for _, id := range ids {
+		idSources := sources[id]
+		l := &Lineage{ID: id, Files: make([]string, 0, len(idSources))}
+		for _, source := range idSources {
+			l.Files = append(l.Files, source.file)
+		}
+
+		if precedence == MergeMostRecent {
+			ranked := make([]*mergeSource, len(idSources))
+			copy(ranked, idSources)
+			sort.SliceStable(ranked, func(i, j int) bool {
+				return result.modTimes[ranked[i].file].After(result.modTimes[ranked[j].file])
+			})
+			idSources = ranked
+		}
+
+		employee, fields := mergeFields(precedence, idSources)
+		if meta := mergeProvenance(idSources); meta != employee.Meta {
+			e := *employee
+			e.Meta = meta
+			employee = &e
+		}
+		l.Fields = fields
+		employees = append(employees, employee)
+		lineage = append(lineage, l)
+		merged[l.Files[0]] = append(merged[l.Files[0]], employee)
+	}
+
+	// the sinks receive each merged employee once, with the first file of its ID.
+	result.fileEmployees = merged
+
+	return employees, lineage
+}
+
+// mergeFields merges the sources ranked from the highest precedence, returning the file of each field.
+func mergeFields(precedence MergePrecedence, sources []*mergeSource) (*entity.Employee, map[string]string) {
+	first := sources[0]
+	if precedence == MergeFileOrder {
+		fields := map[string]string{"id": first.file, "email": first.file, "name": first.file, "salary": first.file}
+		if first.employee.Phone != "" {
+			fields["phone"] = first.file
+		}
+		for name := range first.employee.Attributes {
+			fields[attributeField(name)] = first.file
+		}
+		return first.employee, fields
+	}
+
+	var (
+		merged = &entity.Employee{ID: first.employee.ID}
+		fields = map[string]string{"id": first.file}
+	)
+	for _, source := range sources {
+		e := source.employee
+		if merged.Email == "" && e.Email != "" {
+			merged.Email, fields["email"] = e.Email, source.file
+		}
+		if merged.Name == "" && e.Name != "" {
+			merged.Name, fields["name"] = e.Name, source.file
+		}
+		if merged.Salary == 0 && e.Salary != 0 {
+			merged.Salary, fields["salary"] = e.Salary, source.file
+		}
+		if merged.Phone == "" && e.Phone != "" {
+			merged.Phone, fields["phone"] = e.Phone, source.file
+		}
+		for name, value := range e.Attributes {
+			if _, ok := merged.Attributes[name]; ok {
+				continue
+			}
+			if merged.Attributes == nil {
+				merged.Attributes = make(entity.Attributes, len(e.Attributes))
+			}
+			merged.Attributes[name], fields[attributeField(name)] = value, source.file
+		}
+	}
+
+	return merged, fields
+}
+
+// writeLineageResultFile writes the Lineage of the merged employees.
+func (s *service) writeLineageResultFile(lineage []*Lineage) (string, error) {
+	file, err := json.MarshalIndent(lineage, "", " ")
+	if err != nil {
+		return "", err
+	}
+
+	fileName := s.resultFileName("lineage")
+	if err := s.writeResultFile(fileName, file); err != nil {
+		log.WithFields(log.Fields{
+			"event":  "write_lineage_file_failed",
+			"file":   fileName,
+			"reason": err,
+		}).Error()
+		return fileName, err
+	}
+
+	log.WithFields(log.Fields{
+		"event": "lineage_result_file_wrote",
+		"file":  fileName,
+	}).Info()
+
+	return fileName, nil
+}
+
+// mergedCount returns the number of employees merged from more than one file.
+func mergedCount(lineage []*Lineage) int {
+	var merged int
+	for _, l := range lineage {
+		if len(l.Files) > 1 {
+			merged++
+		}
+	}
+
+	return merged
+}
@@ -0,0 +1,183 @@
+package csv_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vsantosalmeida/csv-parser/entity"
+	errs "github.com/vsantosalmeida/csv-parser/pkg/errors"
+	"github.com/vsantosalmeida/csv-parser/usecase/csv"
+)
+
+// newMergeFiles writes two rosters with the same IDs in a temp dir, the second one is the oldest.
+func newMergeFiles(t *testing.T) (string, []string, map[string]*csv.FilePattern) {
+	dir, err := ioutil.TempDir("", "merge")
+	assert.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	files := []string{filepath.Join(dir, "roster2.csv"), filepath.Join(dir, "roster3.csv")}
+	contents := []string{
+		"Name,Email,Wage,Number,Phone\nJohn Doe,doe@test.com,$10,1,\nMary Jane,mary@test.com,$15,2,555-0102\nMax Topperson,doe@test.com,$11,3,\n",
+		"Name,Email,Wage,Number,Phone\nJohn D.,john@test.com,$12,1,555-0101\nMary Jane,mary@test.com,$16,2,\nMary Dup,dup@test.com,$16,2,\n",
+	}
+	for i, file := range files {
+		assert.NoError(t, ioutil.WriteFile(file, []byte(contents[i]), 0644))
+	}
+	old := time.Now().Add(-time.Hour)
+	assert.NoError(t, os.Chtimes(files[1], old, old))
+
+	pattern := &csv.FilePattern{
+		FirstNameColumn: "Name",
+		SalaryColumn:    "Wage",
+		EmailColumn:     "Email",
+		IDColumn:        "Number",
+		PhoneColumn:     "Phone",
+	}
+
+	return dir, files, map[string]*csv.FilePattern{files[0]: pattern, files[1]: pattern}
+}
+
+func TestService_ParseFiles_Merge(t *testing.T) {
+	tests := []struct {
+		name       string
+		precedence csv.MergePrecedence
+		want       []*entity.Employee
+		wantFields []map[string]int
+	}{
+		{
+			name:       "file order",
+			precedence: csv.MergeFileOrder,
+			want: []*entity.Employee{
+				{ID: "1", Email: "doe@test.com", Name: "John Doe", Salary: 10},
+				{ID: "2", Email: "mary@test.com", Name: "Mary Jane", Salary: 15, Phone: "555-0102"},
+			},
+			wantFields: []map[string]int{
+				{"id": 0, "email": 0, "name": 0, "salary": 0},
+				{"id": 0, "email": 0, "name": 0, "salary": 0, "phone": 0},
+			},
+		},
+		{
+			name:       "non empty",
+			precedence: csv.MergeNonEmpty,
+			want: []*entity.Employee{
+				{ID: "1", Email: "doe@test.com", Name: "John Doe", Salary: 10, Phone: "555-0101"},
+				{ID: "2", Email: "mary@test.com", Name: "Mary Jane", Salary: 15, Phone: "555-0102"},
+			},
+			wantFields: []map[string]int{
+				{"id": 0, "email": 0, "name": 0, "salary": 0, "phone": 1},
+				{"id": 0, "email": 0, "name": 0, "salary": 0, "phone": 0},
+			},
+		},
+		{
+			name:       "most recent",
+			precedence: csv.MergeMostRecent,
+			want: []*entity.Employee{
+				{ID: "1", Email: "doe@test.com", Name: "John Doe", Salary: 10, Phone: "555-0101"},
+				{ID: "2", Email: "mary@test.com", Name: "Mary Jane", Salary: 15, Phone: "555-0102"},
+			},
+			wantFields: []map[string]int{
+				{"id": 0, "email": 0, "name": 0, "salary": 0, "phone": 1},
+				{"id": 0, "email": 0, "name": 0, "salary": 0, "phone": 0},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, files, patterns := newMergeFiles(t)
+			svc, err := csv.NewParser(patterns, csv.WithOutputDir(dir), csv.WithMerge(tt.precedence))
+			assert.NoError(t, err)
+			assert.Empty(t, svc.ParseFiles(files))
+
+			summary := svc.Summary()
+			assert.Equal(t, 2, summary.Employees)
+			assert.Equal(t, 2, summary.Merged)
+			// doe@test.com is used by another ID and "2" is repeated in roster3.csv.
+			assert.Equal(t, 2, summary.BadLines)
+
+			b, err := ioutil.ReadFile(summary.EmployeesFile)
+			assert.NoError(t, err)
+			var got []*entity.Employee
+			assert.NoError(t, json.Unmarshal(b, &got))
+			assert.Equal(t, tt.want, got)
+
+			b, err = ioutil.ReadFile(summary.LineageFile)
+			assert.NoError(t, err)
+			var gotLineage []*csv.Lineage
+			assert.NoError(t, json.Unmarshal(b, &gotLineage))
+			if !assert.Len(t, gotLineage, 2) {
+				return
+			}
+			for i, l := range gotLineage {
+				assert.Equal(t, files, l.Files)
+				wantFields := make(map[string]string)
+				for field, file := range tt.wantFields[i] {
+					wantFields[field] = files[file]
+				}
+				assert.Equal(t, wantFields, l.Fields, l.ID)
+			}
+		})
+	}
+}
+
+func TestService_ParseFiles_MergeMostRecent(t *testing.T) {
+	dir, files, patterns := newMergeFiles(t)
+	// roster3.csv is now the most recent file.
+	assert.NoError(t, os.Chtimes(files[1], time.Now().Add(time.Hour), time.Now().Add(time.Hour)))
+
+	svc, err := csv.NewParser(patterns, csv.WithOutputDir(dir), csv.WithMerge(csv.MergeMostRecent))
+	assert.NoError(t, err)
+	assert.Empty(t, svc.ParseFiles(files))
+
+	b, err := ioutil.ReadFile(svc.Summary().EmployeesFile)
+	assert.NoError(t, err)
+	var got []*entity.Employee
+	assert.NoError(t, json.Unmarshal(b, &got))
+	assert.Equal(t, []*entity.Employee{
+		{ID: "1", Email: "john@test.com", Name: "John D.", Salary: 12, Phone: "555-0101"},
+		{ID: "2", Email: "mary@test.com", Name: "Mary Jane", Salary: 16, Phone: "555-0102"},
+	}, got)
+}
+
+// employeesSink keeps the employees received by file.
+type employeesSink struct {
+	files     []string
+	employees map[string][]*entity.Employee
+}
+
+func (s *employeesSink) WriteFile(file string, employees []*entity.Employee, _ []*csv.BadData) error {
+	if s.employees == nil {
+		s.employees = make(map[string][]*entity.Employee)
+	}
+	s.files = append(s.files, file)
+	s.employees[file] = employees
+	return nil
+}
+
+func TestService_ParseFiles_MergeSink(t *testing.T) {
+	dir, files, patterns := newMergeFiles(t)
+	sink := &employeesSink{}
+	svc, err := csv.NewParser(patterns, csv.WithOutputDir(dir), csv.WithMerge(csv.MergeNonEmpty), csv.WithSink(sink))
+	assert.NoError(t, err)
+	assert.Empty(t, svc.ParseFiles(files))
+
+	// both files have bad lines, but the merged employees are only sent with the first file of each ID.
+	assert.Equal(t, files, sink.files)
+	assert.Equal(t, []*entity.Employee{
+		{ID: "1", Email: "doe@test.com", Name: "John Doe", Salary: 10, Phone: "555-0101"},
+		{ID: "2", Email: "mary@test.com", Name: "Mary Jane", Salary: 15, Phone: "555-0102"},
+	}, sink.employees[files[0]])
+	assert.Empty(t, sink.employees[files[1]])
+}
+
+func TestNewParser_InvalidMerge(t *testing.T) {
+	_, _, patterns := newMergeFiles(t)
+	svc, err := csv.NewParser(patterns, csv.WithMerge("newest"))
+	assert.Nil(t, svc)
+	assert.ErrorIs(t, err, errs.ErrInvalidMerge)
+}
@@ -0,0 +1,62 @@
+package csv
+
+import (
+	"fmt"
+
+	errs "github.com/vsantosalmeida/csv-parser/pkg/errors"
+)
+
+// Policy configures when a run must stop or discard the employees from the processed files.
+//
+// The zero value keeps the default behavior, every file is processed and all the valid employees are written.
+type Policy struct {
+	// FailFast stops the run on the first invalid line, the remaining files are not processed.
+	FailFast bool
+	// MaxBadLines rejects a file with more bad lines than the given value, 0 means no limit.
+	MaxBadLines int
+	// MaxBadRatio rejects a file when the ratio between bad lines and total lines is greater than the
+	// given value, must be between 0 and 1 and 0 means no limit.
+	MaxBadRatio float64
+	// AllOrNothing skips the employees result file when any file finished with an error.
+	AllOrNothing bool
+}
+
+// Option configures optional behaviors of the Parser returned by NewParser.
+type Option func(s *service)
+
+// WithPolicy sets the Policy used by Parser.ParseFiles.
+func WithPolicy(policy Policy) Option {
+	return func(s *service) {
+		s.policy = policy
+	}
+}
+
+// Validate returns an errs.ErrInvalidPolicy error when a limit of the Policy is out of range.
+func (p Policy) Validate() error {
+	if p.MaxBadLines < 0 {
+		return errs.NewError(errs.ErrInvalidPolicy, "max bad lines must not be negative")
+	}
+
+	if p.MaxBadRatio < 0 || p.MaxBadRatio > 1 {
+		return errs.NewError(errs.ErrInvalidPolicy, "max bad ratio must be between 0 and 1")
+	}
+
+	return nil
+}
+
+// thresholdExceeded checks the bad lines of a file against the MaxBadLines and MaxBadRatio limits,
+// returning the cause when one of them is exceeded.
+func (p Policy) thresholdExceeded(badLines, totalLines int) (string, bool) {
+	if p.MaxBadLines > 0 && badLines > p.MaxBadLines {
+		return fmt.Sprintf("%d bad lines, the limit is %d", badLines, p.MaxBadLines), true
+	}
+
+	if p.MaxBadRatio > 0 && totalLines > 0 {
+		ratio := float64(badLines) / float64(totalLines)
+		if ratio > p.MaxBadRatio {
+			return fmt.Sprintf("%d of %d lines are bad, the limit ratio is %.2f", badLines, totalLines, p.MaxBadRatio), true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,54 @@
+package csv
+
+import "context"
+
+// Progress holds the counters of the lines read by a run, updated after each line.
+//
+// The counters are not rolled back when a file is rejected by the run Policy, so Accepted can be greater
+// than the employees written to the result file.
+type Progress struct {
+	// Rows is the number of data lines read, without the headers.
+	Rows int `json:"rows"`
+	// Accepted is the number of lines mapped to an employee.
+	Accepted int `json:"accepted"`
+	// Rejected is the number of lines mapped to a bad data.
+	Rejected int `json:"rejected"`
+}
+
+// ProgressFunc receives the Progress of a run after each line, it is called from the goroutine running
+// the Parser so it must return quickly.
+type ProgressFunc func(progress Progress)
+
+// WithProgress sets the ProgressFunc called by Parser.ParseFiles and Parser.Validate.
+func WithProgress(fn ProgressFunc) Option {
+	return func(s *service) {
+		s.progress = fn
+	}
+}
+
+// WithContext sets the context of the runs, when it is done the file being read and the remaining files
+// are not processed and no result file is written.
+func WithContext(ctx context.Context) Option {
+	return func(s *service) {
+		s.ctx = ctx
+	}
+}
+
+// addProgress updates the counters of the run with a read line and calls the ProgressFunc.
+func (s *service) addProgress(accepted bool) {
+	s.counters.Rows++
+	if accepted {
+		s.counters.Accepted++
+	} else {
+		s.counters.Rejected++
+	}
+
+	if s.progress != nil {
+		s.progress(s.counters)
+	}
+}
+
+// cancelled returns true when the context of the run is done.
+func (s *service) cancelled() bool {
+	return s.ctx != nil && s.ctx.Err() != nil
+}
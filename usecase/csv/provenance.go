@@ -0,0 +1,147 @@
+package csv
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vsantosalmeida/csv-parser/entity"
+	errs "github.com/vsantosalmeida/csv-parser/pkg/errors"
+)
+
+const writeProvenanceFile = "writeProvenanceFile"
+
+// ProvenanceMode is how the entity.Provenance recorded by WithProvenance is written.
+type ProvenanceMode string
+
+const (
+	// ProvenanceEmbedded writes the provenance of each employee in its "_meta" object.
+	ProvenanceEmbedded ProvenanceMode = "embedded"
+	// ProvenanceSidecar writes the provenance of the employees to a provenance result file by ID,
+	// keeping the employees file without the "_meta" object.
+	ProvenanceSidecar ProvenanceMode = "sidecar"
+)
+
+// provenanceLine is an entity.Provenance of the sidecar file with the employee ID.
+type provenanceLine struct {
+	ID string `json:"id"`
+	*entity.Provenance
+}
+
+// WithProvenance records the entity.Provenance of each employee in its Meta, with the file, the line,
+// the source columns of each field and the ID of the run, also set in the Summary.
+//
+// The employees sent to the sinks carry the Meta in both modes.
+func WithProvenance(mode ProvenanceMode) Option {
+	return func(s *service) {
+		s.provenance = mode
+	}
+}
+
+func validateProvenance(mode ProvenanceMode) error {
+	switch mode {
+	case "", ProvenanceEmbedded, ProvenanceSidecar:
+		return nil
+	default:
+		return errs.NewError(errs.ErrInvalidProvenance, string(mode))
+	}
+}
+
+// newProvenance returns the entity.Provenance of an employee read from the line of the file being processed.
+func (s *service) newProvenance(pattern *FilePattern, line int) *entity.Provenance {
+	name := []string{pattern.FirstNameColumn}
+	if pattern.LastNameColumn != "" {
+		name = append(name, pattern.LastNameColumn)
+	}
+
+	columns := map[string][]string{
+		"id":     {pattern.IDColumn},
+		"email":  {pattern.EmailColumn},
+		"name":   name,
+		"salary": {pattern.SalaryColumn},
+	}
+	if pattern.PhoneColumn != "" {
+		columns["phone"] = []string{pattern.PhoneColumn}
+	}
+	for _, a := range pattern.Attributes {
+		columns[attributeField(a.Name)] = []string{a.Column}
+	}
+
+	return &entity.Provenance{
+		RunID:   s.runID,
+		File:    s.file,
+		Line:    line,
+		Columns: columns,
+	}
+}
+
+// mergeProvenance returns the Provenance of an employee merged from the sources, ranked from the highest
+// precedence, the one of the first source with the Provenance of each source.
+func mergeProvenance(sources []*mergeSource) *entity.Provenance {
+	if sources[0].employee.Meta == nil || len(sources) == 1 {
+		return sources[0].employee.Meta
+	}
+
+	meta := *sources[0].employee.Meta
+	meta.Sources = make([]*entity.Provenance, 0, len(sources))
+	for _, source := range sources {
+		meta.Sources = append(meta.Sources, source.employee.Meta)
+	}
+
+	return &meta
+}
+
+// withoutMeta returns copies of the employees without the Meta, written by ProvenanceSidecar.
+func withoutMeta(employees []*entity.Employee) []*entity.Employee {
+	copies := make([]*entity.Employee, 0, len(employees))
+	for _, employee := range employees {
+		e := *employee
+		e.Meta = nil
+		copies = append(copies, &e)
+	}
+
+	return copies
+}
+
+// writeProvenanceResultFile writes the Provenance of each employee by ID, in the employees file order.
+func (s *service) writeProvenanceResultFile(employees []*entity.Employee) (string, error) {
+	lines := make([]*provenanceLine, 0, len(employees))
+	for _, employee := range employees {
+		lines = append(lines, &provenanceLine{ID: employee.ID, Provenance: employee.Meta})
+	}
+
+	file, err := json.MarshalIndent(lines, "", " ")
+	if err != nil {
+		return "", err
+	}
+
+	fileName := s.resultFileName("provenance")
+	if err := s.writeResultFile(fileName, file); err != nil {
+		log.WithFields(log.Fields{
+			"event":  "write_provenance_file_failed",
+			"file":   fileName,
+			"reason": err,
+		}).Error()
+		return fileName, err
+	}
+
+	log.WithFields(log.Fields{
+		"event": "provenance_result_file_wrote",
+		"file":  fileName,
+	}).Info()
+
+	return fileName, nil
+}
+
+// newRunID returns a random ID for the run, or one from the current time when the random bytes fail.
+func newRunID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,116 @@
+package csv_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vsantosalmeida/csv-parser/entity"
+	errs "github.com/vsantosalmeida/csv-parser/pkg/errors"
+	"github.com/vsantosalmeida/csv-parser/usecase/csv"
+)
+
+func TestService_ParseFiles_Provenance(t *testing.T) {
+	var (
+		givenFile         = "test_files/roster1.csv"
+		givenFilePatterns = map[string]*csv.FilePattern{
+			givenFile: {
+				FirstNameColumn: "Name",
+				SalaryColumn:    "Wage",
+				EmailColumn:     "Email",
+				IDColumn:        "Number",
+			},
+		}
+		wantColumns = map[string][]string{
+			"id":     {"Number"},
+			"email":  {"Email"},
+			"name":   {"Name"},
+			"salary": {"Wage"},
+		}
+	)
+
+	t.Run("embedded", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "provenance")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		svc, err := csv.NewParser(givenFilePatterns, csv.WithOutputDir(dir), csv.WithProvenance(csv.ProvenanceEmbedded))
+		assert.NoError(t, err)
+		assert.Empty(t, svc.ParseFiles([]string{givenFile}))
+
+		summary := svc.Summary()
+		assert.NotEmpty(t, summary.RunID)
+		assert.Empty(t, summary.ProvenanceFile)
+
+		b, err := ioutil.ReadFile(summary.EmployeesFile)
+		assert.NoError(t, err)
+		var got []*entity.Employee
+		assert.NoError(t, json.Unmarshal(b, &got))
+		if !assert.Len(t, got, 3) {
+			return
+		}
+		assert.Equal(t, &entity.Provenance{RunID: summary.RunID, File: givenFile, Line: 2, Columns: wantColumns}, got[0].Meta)
+		assert.Equal(t, 4, got[2].Meta.Line)
+	})
+
+	t.Run("sidecar", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "provenance")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		svc, err := csv.NewParser(givenFilePatterns, csv.WithOutputDir(dir), csv.WithProvenance(csv.ProvenanceSidecar))
+		assert.NoError(t, err)
+		assert.Empty(t, svc.ParseFiles([]string{givenFile}))
+
+		summary := svc.Summary()
+		b, err := ioutil.ReadFile(summary.EmployeesFile)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(b), "_meta")
+
+		b, err = ioutil.ReadFile(summary.ProvenanceFile)
+		assert.NoError(t, err)
+		var got []map[string]interface{}
+		assert.NoError(t, json.Unmarshal(b, &got))
+		if !assert.Len(t, got, 3) {
+			return
+		}
+		assert.Equal(t, "1", got[0]["id"])
+		assert.Equal(t, givenFile, got[0]["file"])
+		assert.Equal(t, float64(2), got[0]["line"])
+		assert.Equal(t, summary.RunID, got[0]["run_id"])
+	})
+}
+
+func TestService_ParseFiles_ProvenanceMerge(t *testing.T) {
+	dir, files, patterns := newMergeFiles(t)
+	svc, err := csv.NewParser(patterns, csv.WithOutputDir(dir), csv.WithMerge(csv.MergeFileOrder),
+		csv.WithProvenance(csv.ProvenanceEmbedded))
+	assert.NoError(t, err)
+	assert.Empty(t, svc.ParseFiles(files))
+
+	b, err := ioutil.ReadFile(svc.Summary().EmployeesFile)
+	assert.NoError(t, err)
+	var got []*entity.Employee
+	assert.NoError(t, json.Unmarshal(b, &got))
+	if !assert.Len(t, got, 2) {
+		return
+	}
+
+	meta := got[0].Meta
+	assert.Equal(t, files[0], meta.File)
+	if assert.Len(t, meta.Sources, 2) {
+		assert.Equal(t, files[0], meta.Sources[0].File)
+		assert.Equal(t, files[1], meta.Sources[1].File)
+		assert.Equal(t, 2, meta.Sources[1].Line)
+		assert.Equal(t, []string{"Phone"}, meta.Sources[1].Columns["phone"])
+	}
+}
+
+func TestNewParser_InvalidProvenance(t *testing.T) {
+	_, _, patterns := newMergeFiles(t)
+	svc, err := csv.NewParser(patterns, csv.WithProvenance("inline"))
+	assert.Nil(t, svc)
+	assert.ErrorIs(t, err, errs.ErrInvalidProvenance)
+}
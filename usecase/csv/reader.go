@@ -0,0 +1,121 @@
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+
+	errs "github.com/vsantosalmeida/csv-parser/pkg/errors"
+)
+
+// FormatXLSX is the Format of the Excel workbooks, only supported as input.
+const FormatXLSX Format = "xlsx"
+
+// inputFormats are the Format of the files that can be parsed.
+var inputFormats = map[Format]bool{
+	FormatCSV:    true,
+	FormatXLSX:   true,
+	FormatJSON:   true,
+	FormatNDJSON: true,
+	FormatXML:    true,
+	// FormatFixedWidth has no extension, it is used when the FilePattern has a FixedWidth config.
+	FormatFixedWidth: true,
+}
+
+func validateInputFormat(format Format) error {
+	if format == "" || inputFormats[format] {
+		return nil
+	}
+
+	return errs.NewError(errs.ErrUnsupportedFormat, string(format))
+}
+
+// inputFormat returns the FilePattern Format, or the Format of the file extension when it is empty
+// and the FilePattern has no FixedWidth config.
+// The files with an unknown extension, like ".tsv" or ".txt", are read as CSV.
+func inputFormat(file string, pattern *FilePattern) Format {
+	if pattern != nil && pattern.Format != "" {
+		return pattern.Format
+	}
+
+	if pattern != nil && pattern.FixedWidth != nil {
+		return FormatFixedWidth
+	}
+
+	if f := Format(strings.TrimPrefix(strings.ToLower(filepath.Ext(file)), ".")); inputFormats[f] {
+		return f
+	}
+
+	return FormatCSV
+}
+
+// recordReader reads the lines of an input file as records, the first record is the header
+// with the columns' names.
+type recordReader interface {
+	// Read returns the next record with the line number where it starts in the file, and io.EOF
+	// when there are no more records.
+	//
+	// A malformed line is returned as an *errs.ValidationError, so the next lines can still be read,
+	// any other error means the file can not be read anymore.
+	Read() (record []string, line int, err error)
+}
+
+// newRecordReader returns the recordReader of the file Format, configured by the FilePattern.
+func newRecordReader(r io.Reader, file string, pattern *FilePattern) (recordReader, error) {
+	if pattern == nil {
+		pattern = &FilePattern{}
+	}
+
+	switch inputFormat(file, pattern) {
+	case FormatXLSX:
+		return newXLSXRecordReader(r, pattern.Sheet)
+	case FormatJSON, FormatNDJSON:
+		return newJSONRecordReader(r, inputFormat(file, pattern), pattern)
+	case FormatXML:
+		return newXMLRecordReader(r, pattern)
+	case FormatFixedWidth:
+		return newFixedWidthRecordReader(r, pattern.FixedWidth, pattern.Encoding)
+	default:
+		return newCSVRecordReader(r, pattern.Dialect, pattern.Encoding)
+	}
+}
+
+// csvRecordReader is a recordReader for CSV files.
+type csvRecordReader struct {
+	reader *csv.Reader
+}
+
+// newCSVRecordReader transcodes r to UTF-8 and reads it as a CSV file following the Dialect.
+func newCSVRecordReader(r io.Reader, dialect *Dialect, encodingName string) (*csvRecordReader, error) {
+	decoded, _, err := newDecoder(r, encodingName)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := newReader(decoded, dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	return &csvRecordReader{reader: reader}, nil
+}
+
+func (r *csvRecordReader) Read() ([]string, int, error) {
+	record, err := r.reader.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, parseErr.StartLine, &errs.ValidationError{
+				Field: errs.FieldRecord,
+				Line:  parseErr.StartLine,
+				Code:  errs.NewError(errs.ErrMalformedLine, parseErr.Err.Error()),
+			}
+		}
+		return nil, 0, err
+	}
+
+	line, _ := r.reader.FieldPos(0)
+	return record, line, nil
+}
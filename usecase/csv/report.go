@@ -0,0 +1,81 @@
+package csv
+
+const defaultReportSamples = 5
+
+// Report is the result of a dry-run made by Parser.Validate, describing the bad data of each file
+// without writing any result file.
+type Report struct {
+	Summary *Summary `json:"summary"`
+	// Reasons is the number of bad lines by reason of all the files.
+	Reasons map[string]int `json:"reasons,omitempty"`
+	// Files holds the report of each processed file by its name.
+	Files map[string]*FileReport `json:"files,omitempty"`
+}
+
+// FileReport describes the bad data of a single file in a Report.
+type FileReport struct {
+	// Lines is the number of data lines of the file, without the header.
+	Lines    int `json:"lines"`
+	BadLines int `json:"bad_lines"`
+	// Reasons is the number of bad lines by reason.
+	Reasons map[string]int `json:"reasons,omitempty"`
+	// Columns is the number of invalid values by the file column name.
+	Columns map[string]int `json:"columns,omitempty"`
+	// Samples holds the first bad lines of the file.
+	Samples []*BadData `json:"samples,omitempty"`
+	// BadData holds all the bad lines of the file.
+	BadData []*BadData `json:"-"`
+}
+
+// WithReportSamples sets the max number of bad lines of each file kept as samples by a Report, 5 by default.
+func WithReportSamples(samples int) Option {
+	return func(s *service) {
+		if samples < 0 {
+			samples = 0
+		}
+		s.reportSamples = samples
+	}
+}
+
+func newReport(summary *Summary, result *runResult, samples int) *Report {
+	report := &Report{
+		Summary: summary,
+		Reasons: make(map[string]int),
+		Files:   make(map[string]*FileReport),
+	}
+
+	for file, lines := range result.lines {
+		report.Files[file] = &FileReport{Lines: lines}
+	}
+
+	for file, badData := range result.badData {
+		fileReport, ok := report.Files[file]
+		if !ok {
+			fileReport = &FileReport{}
+			report.Files[file] = fileReport
+		}
+
+		fileReport.BadLines = len(badData)
+		fileReport.BadData = badData
+		fileReport.Reasons = make(map[string]int)
+		fileReport.Columns = make(map[string]int)
+		if len(badData) > samples {
+			fileReport.Samples = badData[:samples]
+		} else {
+			fileReport.Samples = badData
+		}
+
+		for _, bd := range badData {
+			for _, reason := range bd.Reasons {
+				fileReport.Reasons[reason]++
+				report.Reasons[reason]++
+			}
+
+			for _, e := range bd.Errors {
+				fileReport.Columns[e.Column]++
+			}
+		}
+	}
+
+	return report
+}
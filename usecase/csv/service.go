@@ -1,13 +1,15 @@
 package csv
 
 import (
-	"encoding/csv"
+	"context"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
+	"io"
 	"net/mail"
-	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/vsantosalmeida/csv-parser/entity"
@@ -17,158 +19,509 @@ import (
 type service struct {
 	patterns map[string]*FilePattern
 	inMemDB  map[string]string
+	policy   Policy
+	// reportSamples is the max number of bad lines of each file kept as samples by a Report.
+	reportSamples int
+	// fileKeys holds the keys stored in the inMemDB by the file being processed,
+	// used to release them when the file is rejected.
+	fileKeys []string
+	summary  *Summary
+	// outputDir is the directory of the result files, the working directory when empty.
+	outputDir string
+	// ctx cancels the run when it is done, runs are not cancelled when nil.
+	ctx      context.Context
+	progress ProgressFunc
+	// counters holds the Progress of the current run.
+	counters Progress
+	sinks    []Sink
+	// previous is the employees snapshot compared by WithDiff, no diff is written when nil.
+	previous []*entity.Employee
+	// merge is the precedence of WithMerge, the IDs are unique by run when empty.
+	merge MergePrecedence
+	// fileIDs holds the IDs of the file being processed, used by WithMerge to keep them unique by file.
+	fileIDs map[string]struct{}
+	// provenance is the mode of WithProvenance, runID the ID of the current run recorded in the
+	// entity.Provenance and file the file being processed.
+	provenance ProvenanceMode
+	runID      string
+	file       string
 }
 
 const (
 	writeEmployeesFile = "writeEmployeesFile"
-	writeBadDataFile   = "writeEmployeesFile"
+	writeBadDataFile   = "writeBadDataFile"
 )
 
 // NewParser returns a Parser interface to process CSV files.
 //
-// a map[string]*FilePattern is required to translate the columns names for each file.
-func NewParser(filePatternMap map[string]*FilePattern) (Parser, error) {
+// a map[string]*FilePattern is required to translate the columns names for each file,
+// the optional behaviors like a run Policy are set by the opts.
+func NewParser(filePatternMap map[string]*FilePattern, opts ...Option) (Parser, error) {
 	if err := validateFilePattern(filePatternMap); err != nil {
 		return nil, err
 	}
 
-	return &service{
-		patterns: filePatternMap,
-		inMemDB:  make(map[string]string),
-	}, nil
+	s := &service{
+		patterns:      filePatternMap,
+		inMemDB:       make(map[string]string),
+		reportSamples: defaultReportSamples,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if err := s.policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := validateMerge(s.merge); err != nil {
+		return nil, err
+	}
+
+	if err := validateProvenance(s.provenance); err != nil {
+		return nil, err
+	}
+
+	return s, nil
 }
 
 func (s *service) ParseFiles(files []string) (errors map[string]error) {
-	errors = make(map[string]error)
-	badDataResult := make(map[string][]*BadData)
-	employeesResult := make([]*entity.Employee, 0)
+	result := s.parse(files)
+	employeesResult, badDataResult, errors := result.employees, result.badData, result.errors
+
+	if s.cancelled() {
+		log.WithFields(log.Fields{
+			"event":       "result_files_skipped",
+			"file_errors": len(errors),
+		}).Error("the result files were not written because the run was cancelled")
+		s.summary = newSummary(files, 0, badDataResult, errors)
+		return
+	}
+
+	// the errors of the result files are keyed by the write* constants and report the path of the file.
+	badDataFileName, err := s.writeBadDataResultFile(badDataResult)
+	if err != nil {
+		errors[writeBadDataFile] = &errs.FileError{Path: badDataFileName, Op: errs.OpWrite, Err: errs.NewError(errs.ErrWriteFile, err.Error())}
+		badDataFileName = ""
+	}
+
+	var (
+		employeesFileName string
+		employeesWritten  bool
+	)
+	skipEmployees := s.policy.AllOrNothing && len(errors) != 0
+	if skipEmployees {
+		log.WithFields(log.Fields{
+			"event":       "employees_file_skipped",
+			"file_errors": len(errors),
+		}).Error("the employees result file was not written because a file finished with errors")
+		errors[writeEmployeesFile] = &errs.FileError{Path: s.resultFileName("employee"), Op: errs.OpWrite, Err: errs.ErrAllOrNothing}
+		employeesResult = employeesResult[:0]
+	} else if employeesFileName, err = s.writeEmployeesResultFile(s.employeesToWrite(employeesResult)); err != nil {
+		errors[writeEmployeesFile] = &errs.FileError{Path: employeesFileName, Op: errs.OpWrite, Err: errs.NewError(errs.ErrWriteFile, err.Error())}
+		employeesFileName = ""
+		employeesResult = employeesResult[:0]
+	} else {
+		employeesWritten = true
+	}
+
+	var provenanceFileName string
+	if s.provenance == ProvenanceSidecar && len(employeesResult) != 0 && employeesWritten {
+		if provenanceFileName, err = s.writeProvenanceResultFile(employeesResult); err != nil {
+			errors[writeProvenanceFile] = &errs.FileError{Path: provenanceFileName, Op: errs.OpWrite, Err: errs.NewError(errs.ErrWriteFile, err.Error())}
+			provenanceFileName = ""
+		}
+	}
+
+	var lineageFileName string
+	if len(result.lineage) != 0 && employeesWritten {
+		if lineageFileName, err = s.writeLineageResultFile(result.lineage); err != nil {
+			errors[writeLineageFile] = &errs.FileError{Path: lineageFileName, Op: errs.OpWrite, Err: errs.NewError(errs.ErrWriteFile, err.Error())}
+			lineageFileName = ""
+		}
+	}
+
+	var (
+		diff         *Diff
+		diffFileName string
+	)
+	if s.previous != nil && employeesWritten {
+		if diff, diffFileName, err = s.writeDiffResultFile(employeesResult); err != nil {
+			errors[writeDiffFile] = &errs.FileError{Path: diffFileName, Op: errs.OpWrite, Err: errs.NewError(errs.ErrWriteFile, err.Error())}
+			diffFileName = ""
+		}
+	}
+	s.writeSinks(result, skipEmployees)
+
+	s.summary = newSummary(files, len(employeesResult), badDataResult, errors)
+	s.summary.EmployeesFile = employeesFileName
+	s.summary.BadDataFile = badDataFileName
+	s.summary.RunID = s.runID
+	s.summary.ProvenanceFile = provenanceFileName
+	if employeesWritten && len(result.lineage) != 0 {
+		s.summary.Merged = mergedCount(result.lineage)
+		s.summary.LineageFile = lineageFileName
+	}
+	if diff != nil {
+		s.summary.Diff = &DiffSummary{
+			Added:     len(diff.Added),
+			Removed:   len(diff.Removed),
+			Changed:   len(diff.Changed),
+			Unchanged: diff.Unchanged,
+			File:      diffFileName,
+		}
+	}
+	s.writeSinkSummaries(errors)
+
+	log.WithFields(log.Fields{
+		"event":               "parse_files_finished",
+		"file_errors":         len(errors),
+		"employees_processed": len(employeesResult),
+	}).Info()
+
+	return
+}
+
+// employeesToWrite returns the employees of the employees result file, without the Meta with ProvenanceSidecar.
+func (s *service) employeesToWrite(employees []*entity.Employee) []*entity.Employee {
+	if s.provenance == ProvenanceSidecar {
+		return withoutMeta(employees)
+	}
+
+	return employees
+}
+
+func (s *service) Validate(files []string) (report *Report, errors map[string]error) {
+	result := s.parse(files)
+	s.summary = newSummary(files, len(result.employees), result.badData, result.errors)
+	report = newReport(s.summary, result, s.reportSamples)
+
+	log.WithFields(log.Fields{
+		"event":           "validate_files_finished",
+		"file_errors":     len(result.errors),
+		"valid_employees": len(result.employees),
+	}).Info()
+
+	return report, result.errors
+}
+
+func (s *service) Summary() *Summary {
+	return s.summary
+}
+
+// runResult holds what was mapped from the files by parse.
+type runResult struct {
+	employees []*entity.Employee
+	badData   map[string][]*BadData
+	// lines is the number of data lines of each processed file, without the header.
+	lines map[string]int
+	// files holds the processed files in order and fileEmployees the employees of each accepted file.
+	files         []string
+	fileEmployees map[string][]*entity.Employee
+	// modTimes holds the modification time of each accepted file, and lineage the Lineage of the
+	// employees merged by WithMerge.
+	modTimes map[string]time.Time
+	lineage  []*Lineage
+	errors   map[string]error
+}
+
+// parse reads and maps each file to employees or bad data, following the run Policy,
+// the archives are expanded and each member is parsed as a file.
+//
+// The IDs and e-mails are unique by run, so the inMemDB is cleaned before processing the files.
+func (s *service) parse(files []string) *runResult {
+	var (
+		errors = make(map[string]error)
+		result = &runResult{
+			employees:     make([]*entity.Employee, 0),
+			badData:       make(map[string][]*BadData),
+			lines:         make(map[string]int),
+			fileEmployees: make(map[string][]*entity.Employee),
+			modTimes:      make(map[string]time.Time),
+			errors:        errors,
+		}
+	)
+	s.inMemDB = make(map[string]string)
+	s.counters = Progress{}
+	s.runID = ""
+	if s.provenance != "" {
+		s.runID = newRunID()
+	}
 
 	log.WithFields(log.Fields{
 		"event": "processing_files",
 		"total": len(files),
 		"files": files,
 	}).Debug()
+	var aborted bool
 	for _, file := range files {
-		csvFile, err := os.Open(file)
-		if err != nil {
-			log.WithFields(log.Fields{
-				"event":  "open_file_failed",
-				"file":   file,
-				"reason": err,
-			}).Error("could not open the file")
-			errors[file] = errs.NewError(errs.ErrOpeningFile, err.Error())
+		if !aborted && s.cancelled() {
+			aborted = true
+			errors[file] = &errs.FileError{Path: file, Op: errs.OpParse, Err: errs.ErrRunCancelled}
+			continue
+		}
+		if aborted {
+			errors[file] = &errs.FileError{Path: file, Op: errs.OpParse, Err: errs.ErrRunAborted}
 			continue
 		}
 
-		records, err := csv.NewReader(csvFile).ReadAll()
+		err := walkFile(file, func(input *inputFile) {
+			if aborted {
+				errors[input.key] = &errs.FileError{Path: input.key, Op: errs.OpParse, Err: errs.ErrRunAborted}
+				return
+			}
+			aborted = s.parseInput(input, result)
+		})
 		if err != nil {
 			log.WithFields(log.Fields{
-				"event":  "read_file_failed",
+				"event":  "open_file_failed",
 				"file":   file,
 				"reason": err,
-			}).Error("could not read the file in csv format")
-			errors[file] = errs.NewError(errs.ErrReadingFile, err.Error())
-			continue
+			}).Error("could not open the file")
+			errors[file] = err
 		}
-		csvFile.Close()
+	}
 
-		filePattern, ok := s.patterns[file]
-		if !ok {
-			log.WithFields(log.Fields{
-				"event": "file_pattern_not_found",
-				"file":  file,
-			}).Error("a file pattern was not found to process the given csv file")
-			errors[file] = errs.ErrUnprocessableFile
-			continue
-		}
+	if s.merge != "" {
+		result.employees, result.lineage = mergeEmployees(s.merge, result)
+	}
+
+	return result
+}
+
+// parseInput maps the file to employees or bad data in the runResult, returning true when the run
+// must be stopped by the Policy.
+//
+// The file pattern is found by findPattern.
+func (s *service) parseInput(input *inputFile, result *runResult) (stop bool) {
+	file := input.key
+	// a missing file pattern is only reported after reading the file, so the default dialect
+	// and encoding are used.
+	filePattern, ok := findPattern(s.patterns, input)
+
+	reader, header, err := openRecords(input.reader, input.name, filePattern)
+	if reader != nil {
+		defer closeRecords(reader)
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"event":  "read_file_failed",
+			"file":   file,
+			"reason": err,
+		}).Error("could not read the file in csv format")
+		result.errors[file] = &errs.FileError{Path: file, Op: errs.OpRead, Err: errs.NewError(errs.ErrReadingFile, err.Error())}
+		return false
+	}
 
+	if !ok {
 		log.WithFields(log.Fields{
-			"event": "processing_file",
+			"event": "file_pattern_not_found",
 			"file":  file,
-		}).Info()
+		}).Error("a file pattern was not found to process the given csv file")
+		result.errors[file] = &errs.FileError{Path: file, Op: errs.OpFindPattern, Err: errs.ErrUnprocessableFile}
+		return false
+	}
 
-		employees, badData := s.mapEmployeeOrBadData(records, filePattern)
-		employeesResult = append(employeesResult, employees...)
-		if len(badData) != 0 {
-			log.WithFields(log.Fields{
-				"event": "file_processed_with_bad_data",
-				"file":  file,
-			}).Warn("some lines was not successfully processed")
-			badDataResult[file] = badData
-		}
+	log.WithFields(log.Fields{
+		"event": "processing_file",
+		"file":  file,
+	}).Info()
 
+	s.fileKeys = nil
+	s.fileIDs = make(map[string]struct{})
+	s.file = file
+	employees, badData, fileLines, err := s.mapEmployeeOrBadData(header, reader, filePattern)
+	if err == errs.ErrRunCancelled {
 		log.WithFields(log.Fields{
-			"event": "file_processed",
+			"event": "run_cancelled",
 			"file":  file,
-		}).Info("file processed without critical errors")
+		}).Error("the run was cancelled while reading the file")
+		s.releaseFileKeys()
+		result.errors[file] = &errs.FileError{Path: file, Op: errs.OpParse, Err: errs.ErrRunCancelled}
+		return true
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"event":  "read_file_failed",
+			"file":   file,
+			"reason": err,
+		}).Error("could not read the file in csv format")
+		s.releaseFileKeys()
+		result.errors[file] = &errs.FileError{Path: file, Op: errs.OpRead, Err: errs.NewError(errs.ErrReadingFile, err.Error())}
+		return false
 	}
+	result.lines[file] = fileLines
+	result.files = append(result.files, file)
 
-	if err := s.writeBadDataResultFile(badDataResult); err != nil {
-		errors[writeBadDataFile] = errs.NewError(errs.ErrWriteFile, err.Error())
+	if len(badData) != 0 {
+		log.WithFields(log.Fields{
+			"event": "file_processed_with_bad_data",
+			"file":  file,
+		}).Warn("some lines was not successfully processed")
+		result.badData[file] = badData
+	}
+
+	if s.policy.FailFast && len(badData) != 0 {
+		log.WithFields(log.Fields{
+			"event": "run_stopped_by_fail_fast",
+			"file":  file,
+			"line":  badData[0].Line,
+		}).Error("the run was stopped on the first invalid line")
+		s.releaseFileKeys()
+		result.errors[file] = &errs.FileError{Path: file, Op: errs.OpParse, Err: errs.NewError(errs.ErrFailFast, fmt.Sprintf("line %s", badData[0].Line))}
+		return true
 	}
 
-	if err := s.writeEmployeesResultFile(employeesResult); err != nil {
-		errors[writeEmployeesFile] = errs.NewError(errs.ErrWriteFile, err.Error())
+	if cause, exceeded := s.policy.thresholdExceeded(len(badData), fileLines); exceeded {
+		log.WithFields(log.Fields{
+			"event":  "file_rejected_by_threshold",
+			"file":   file,
+			"reason": cause,
+		}).Error("the file exceeded the bad data threshold")
+		s.releaseFileKeys()
+		result.errors[file] = &errs.FileError{Path: file, Op: errs.OpParse, Err: errs.NewError(errs.ErrBadDataThreshold, cause)}
+		return false
 	}
 
+	result.employees = append(result.employees, employees...)
+	result.fileEmployees[file] = employees
+	result.modTimes[file] = input.modTime
+
 	log.WithFields(log.Fields{
-		"event":               "parse_files_finished",
-		"file_errors":         len(errors),
-		"employees_processed": len(employeesResult),
-	}).Info()
+		"event": "file_processed",
+		"file":  file,
+	}).Info("file processed without critical errors")
 
-	return
+	return false
 }
 
-func (s *service) mapEmployeeOrBadData(records [][]string, pattern *FilePattern) (employees []*entity.Employee, badData []*BadData) {
-	header := make(map[int]string)
+// openRecords creates a recordReader for the file and reads its header.
+func openRecords(r io.Reader, file string, pattern *FilePattern) (recordReader, []string, error) {
+	reader, err := newRecordReader(r, file, pattern)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	for i, line := range records {
-		if i == 0 {
-			for j, columnName := range line {
-				header[j] = columnName
+	header, _, err := reader.Read()
+	if err != nil {
+		closeRecords(reader)
+		return nil, nil, err
+	}
+
+	return reader, header, nil
+}
+
+// closeRecords closes the reader when it holds an open resource, like the worksheet of a XLSX workbook.
+func closeRecords(reader recordReader) {
+	if c, ok := reader.(io.Closer); ok {
+		c.Close()
+	}
+}
+
+// mapEmployeeOrBadData reads the records after the header until the end of the file, mapping each one to an
+// employee or a bad data, and returns the number of lines read.
+//
+// A malformed line is also a bad data, the error is only returned when the file can not be read anymore
+// or errs.ErrRunCancelled when the run was cancelled.
+func (s *service) mapEmployeeOrBadData(header []string, reader recordReader, pattern *FilePattern) (
+	employees []*entity.Employee, badData []*BadData, lines int, err error) {
+	for {
+		if s.cancelled() {
+			err = errs.ErrRunCancelled
+			return
+		}
+
+		record, line, readErr := reader.Read()
+		if readErr == io.EOF {
+			return
+		}
+
+		var (
+			employee       *entity.Employee
+			validationErrs []*errs.ValidationError
+			ok             bool
+			malformed      *errs.ValidationError
+			id             string
+		)
+		switch {
+		case stderrors.As(readErr, &malformed):
+			log.WithFields(log.Fields{
+				"event":  "malformed_line",
+				"line":   line,
+				"reason": malformed.Code,
+			}).Warn("the line could not be parsed")
+			validationErrs = append(validationErrs, malformed)
+		case readErr != nil:
+			err = readErr
+			return
+		default:
+			if j := invalidEncoding(record); j >= 0 {
+				// with a negative FieldsPerRecord the line can have more columns than the header.
+				var column string
+				if j < len(header) {
+					column = header[j]
+				}
+				log.WithFields(log.Fields{
+					"event":  "invalid_encoding",
+					"line":   line,
+					"column": column,
+				}).Warn("the line have bytes that could not be decoded")
+				validationErrs = append(validationErrs, newValidationError(errs.FieldRecord, column, line, record[j], errs.ErrInvalidEncoding))
+				break
 			}
-		} else {
-			employeeMap := make(map[string]string)
 
-			for k, value := range line {
-				propertyName := header[k]
-				employeeMap[propertyName] = value
+			employeeMap := make(map[string]string)
+			for k, value := range record {
+				if k < len(header) {
+					employeeMap[header[k]] = value
+				}
 			}
 
+			id = strings.TrimSpace(employeeMap[pattern.IDColumn])
 			log.WithFields(log.Fields{
 				"event": "building_new_employee",
-				"line":  i + 1,
+				"line":  line,
 			}).Info("")
-			employee, reasons, ok := s.buildEmployee(employeeMap, pattern)
-			if !ok {
-				log.WithFields(log.Fields{
-					"event": "unprocessable_line",
-					"line":  i + 1,
-				}).Warn("the line have invalid properties")
-				badData = append(badData, &BadData{
-					Line:    json.Number(fmt.Sprintf("%d", i+1)),
-					Reasons: reasons,
-				})
-				continue
-			}
+			employee, validationErrs, ok = s.buildEmployee(employeeMap, pattern, line)
+		}
+		lines++
+		s.addProgress(ok)
 
-			employees = append(employees, employee)
+		if !ok {
+			log.WithFields(log.Fields{
+				"event": "unprocessable_line",
+				"line":  line,
+			}).Warn("the line have invalid properties")
+			reasons := make([]string, 0, len(validationErrs))
+			for _, e := range validationErrs {
+				reasons = append(reasons, e.Code.Error())
+			}
+			badData = append(badData, &BadData{
+				Line:    json.Number(strconv.Itoa(line)),
+				Reasons: reasons,
+				Errors:  validationErrs,
+				ID:      id,
+			})
+			if s.policy.FailFast {
+				return
+			}
+			continue
 		}
-	}
 
-	return
+		employees = append(employees, employee)
+	}
 }
 
-func (s *service) buildEmployee(employeeMap map[string]string, pattern *FilePattern) (
-	employee *entity.Employee, reasons []string, ok bool) {
+func (s *service) buildEmployee(employeeMap map[string]string, pattern *FilePattern, line int) (
+	employee *entity.Employee, validationErrs []*errs.ValidationError, ok bool) {
 	name, err := buildAndValidateName(employeeMap[pattern.FirstNameColumn], employeeMap[pattern.LastNameColumn])
 	if err != nil {
 		log.WithFields(log.Fields{
 			"event":  "name_validation_failed",
 			"reason": err,
 		}).Error("error when validating employee name")
-		reasons = append(reasons, err.Error())
+		validationErrs = append(validationErrs, newValidationError(errs.FieldName, pattern.FirstNameColumn, line, employeeMap[pattern.FirstNameColumn], err))
 	}
 
 	salary, err := buildAndValidateSalary(employeeMap[pattern.SalaryColumn])
@@ -177,16 +530,16 @@ func (s *service) buildEmployee(employeeMap map[string]string, pattern *FilePatt
 			"event":  "salary_validation_failed",
 			"reason": err,
 		}).Error("error when validating employee salary")
-		reasons = append(reasons, err.Error())
+		validationErrs = append(validationErrs, newValidationError(errs.FieldSalary, pattern.SalaryColumn, line, employeeMap[pattern.SalaryColumn], err))
 	}
 
-	email, err := s.trimAndValidateEmail(employeeMap[pattern.EmailColumn])
+	email, err := s.trimAndValidateEmail(employeeMap[pattern.EmailColumn], strings.TrimSpace(employeeMap[pattern.IDColumn]))
 	if err != nil {
 		log.WithFields(log.Fields{
 			"event":  "email_validation_failed",
 			"reason": err,
 		}).Error("error when validating employee e-mail")
-		reasons = append(reasons, err.Error())
+		validationErrs = append(validationErrs, newValidationError(errs.FieldEmail, pattern.EmailColumn, line, employeeMap[pattern.EmailColumn], err))
 	}
 
 	id, err := s.validateID(employeeMap[pattern.IDColumn])
@@ -195,12 +548,15 @@ func (s *service) buildEmployee(employeeMap map[string]string, pattern *FilePatt
 			"event":  "id_validation_failed",
 			"reason": err,
 		}).Error("error when validating employee ID")
-		reasons = append(reasons, err.Error())
+		validationErrs = append(validationErrs, newValidationError(errs.FieldID, pattern.IDColumn, line, employeeMap[pattern.IDColumn], err))
 	}
 
 	phone := employeeMap[pattern.PhoneColumn]
 
-	if len(reasons) != 0 {
+	attributes, attributeErrs := buildAttributes(employeeMap, pattern, line)
+	validationErrs = append(validationErrs, attributeErrs...)
+
+	if len(validationErrs) != 0 {
 		return
 	}
 
@@ -211,6 +567,10 @@ func (s *service) buildEmployee(employeeMap map[string]string, pattern *FilePatt
 		Salary: salary,
 		Phone:  phone,
 	}
+	employee.Attributes = attributes
+	if s.provenance != "" {
+		employee.Meta = s.newProvenance(pattern, line)
+	}
 
 	ok = true
 
@@ -222,17 +582,48 @@ func (s *service) buildEmployee(employeeMap map[string]string, pattern *FilePatt
 	return
 }
 
-func (s *service) trimAndValidateEmail(email string) (string, error) {
+// store saves a key in the inMemDB with the ID owning it and keeps track of it as a key from the file
+// being processed.
+func (s *service) store(key, owner string) {
+	s.inMemDB[key] = owner
+	s.fileKeys = append(s.fileKeys, key)
+}
+
+// releaseFileKeys removes the keys stored by a rejected file from the inMemDB,
+// so the IDs and e-mails can still be used by the next files.
+func (s *service) releaseFileKeys() {
+	for _, key := range s.fileKeys {
+		delete(s.inMemDB, key)
+	}
+	s.fileKeys = nil
+}
+
+func newValidationError(field, column string, line int, value string, code error) *errs.ValidationError {
+	return &errs.ValidationError{
+		Field:  field,
+		Column: column,
+		Line:   line,
+		Value:  value,
+		Code:   code,
+	}
+}
+
+// trimAndValidateEmail validates the e-mail of the employee with the given ID, with WithMerge an e-mail
+// stored by another file with the same ID is not a violation.
+func (s *service) trimAndValidateEmail(email, id string) (string, error) {
 	email = strings.Trim(email, " ")
 	if _, err := mail.ParseAddress(email); err != nil {
 		return "", errs.ErrInvalidEmailFormat
 	}
 
-	if _, ok := s.inMemDB[email]; ok {
+	if owner, ok := s.inMemDB[email]; ok {
+		if s.merge != "" && owner == id {
+			return email, nil
+		}
 		return "", errs.ErrEmailConstraintViolation
 	}
 
-	s.inMemDB[email] = ""
+	s.store(email, id)
 
 	return email, nil
 }
@@ -243,11 +634,19 @@ func (s *service) validateID(id string) (string, error) {
 		return "", errs.ErrInvalidIDValue
 	}
 
+	if s.merge != "" {
+		if _, ok := s.fileIDs[id]; ok {
+			return "", errs.ErrIDConstraintViolation
+		}
+		s.fileIDs[id] = struct{}{}
+		return id, nil
+	}
+
 	if _, ok := s.inMemDB[id]; ok {
 		return "", errs.ErrIDConstraintViolation
 	}
 
-	s.inMemDB[id] = ""
+	s.store(id, id)
 
 	return id, nil
 }
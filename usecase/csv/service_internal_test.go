@@ -0,0 +1,89 @@
+package csv
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vsantosalmeida/csv-parser/pkg/errors"
+)
+
+func TestService_mapEmployeeOrBadData_ValidationErrors(t *testing.T) {
+	var (
+		givenFile = "Name,Email,Wage,Number\n" +
+			"John Doe,doe@test.com,$10.00,1\n" +
+			"Mary Jane,marytest.com,$0,2\n"
+		givenPattern = &FilePattern{
+			FirstNameColumn: "Name",
+			SalaryColumn:    "Wage",
+			EmailColumn:     "Email",
+			IDColumn:        "Number",
+		}
+		want = []*errors.ValidationError{
+			{
+				Field:  errors.FieldSalary,
+				Column: "Wage",
+				Line:   3,
+				Value:  "$0",
+				Code:   errors.ErrInvalidSalaryValue,
+			},
+			{
+				Field:  errors.FieldEmail,
+				Column: "Email",
+				Line:   3,
+				Value:  "marytest.com",
+				Code:   errors.ErrInvalidEmailFormat,
+			},
+		}
+	)
+
+	reader, header, err := openRecords(strings.NewReader(givenFile), "roster.csv", &FilePattern{Encoding: EncodingUTF8})
+	assert.NoError(t, err)
+
+	svc := service{inMemDB: make(map[string]string)}
+	employees, badData, lines, err := svc.mapEmployeeOrBadData(header, reader, givenPattern)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, lines)
+	assert.Len(t, employees, 1)
+	if assert.Len(t, badData, 1) {
+		assert.Equal(t, want, badData[0].Errors)
+		assert.Equal(t, []string{errors.ErrInvalidSalaryValue.Error(), errors.ErrInvalidEmailFormat.Error()}, badData[0].Reasons)
+	}
+}
+
+func TestService_mapEmployeeOrBadData_MalformedLines(t *testing.T) {
+	var (
+		givenFile = "Name,Email,Wage,Number\n" +
+			"John Doe,doe@test.com,$10.00,1\n" +
+			"Mary Jane,mary@test.com\n" +
+			"Max \"Paul,max@test.com,$5.00,3\n" +
+			"Ann Lee,ann@test.com,$7.00,4\n"
+		givenPattern = &FilePattern{
+			FirstNameColumn: "Name",
+			SalaryColumn:    "Wage",
+			EmailColumn:     "Email",
+			IDColumn:        "Number",
+		}
+	)
+
+	reader, header, err := openRecords(strings.NewReader(givenFile), "roster.csv", &FilePattern{Encoding: EncodingUTF8})
+	assert.NoError(t, err)
+
+	svc := service{inMemDB: make(map[string]string)}
+	employees, badData, lines, err := svc.mapEmployeeOrBadData(header, reader, givenPattern)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, lines)
+	assert.Len(t, employees, 2)
+	if assert.Len(t, badData, 2) {
+		for i, line := range []int{3, 4} {
+			assert.Equal(t, json.Number(strconv.Itoa(line)), badData[i].Line)
+			if assert.Len(t, badData[i].Errors, 1) {
+				assert.Equal(t, errors.FieldRecord, badData[i].Errors[0].Field)
+				assert.Equal(t, line, badData[i].Errors[0].Line)
+				assert.ErrorIs(t, badData[i].Errors[0], errors.ErrMalformedLine)
+			}
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package csv_test
 
 import (
+	"context"
 	"encoding/json"
 	"io/ioutil"
 	"os"
@@ -408,27 +409,1281 @@ func TestService_ParseFiles_ExampleFile5(t *testing.T) {
 	deleteFiles(files, t)
 }
 
+func TestService_ParseFiles_Dialect(t *testing.T) {
+	var (
+		givenFilePatterns = map[string]*csv.FilePattern{
+			"test_files/roster6.csv": {
+				FirstNameColumn: "first name",
+				LastNameColumn:  "last name",
+				SalaryColumn:    "Rate",
+				EmailColumn:     "e-mail",
+				IDColumn:        "Employee Number",
+				Dialect: &csv.Dialect{
+					Delimiter: csv.DelimiterAuto,
+					Comment:   "#",
+				},
+			},
+			"test_files/roster7.tsv": {
+				FirstNameColumn: "Name",
+				SalaryColumn:    "Wage",
+				EmailColumn:     "Email",
+				IDColumn:        "Number",
+				Dialect: &csv.Dialect{
+					Delimiter:        "\t",
+					LazyQuotes:       true,
+					TrimLeadingSpace: true,
+				},
+			},
+		}
+		files = []string{"test_files/roster6.csv", "test_files/roster7.tsv"}
+
+		wantEmployees = []*entity.Employee{
+			{
+				ID:     "RT1",
+				Email:  "doe@test.com",
+				Name:   "John Doe",
+				Salary: 10,
+			},
+			{
+				ID:     "RT2",
+				Email:  "mary@tes.com",
+				Name:   "Mary; Ann Jane",
+				Salary: 15,
+			},
+			{
+				ID:     "1",
+				Email:  "doe@test.com",
+				Name:   `John "JD" Doe`,
+				Salary: 10,
+			},
+			{
+				ID:     "2",
+				Email:  "mary@tes.com",
+				Name:   "Mary Jane",
+				Salary: 15,
+			},
+		}
+
+		wantBadData = map[string][]*csv.BadData{
+			"test_files/roster6.csv": {
+				{
+					Line:    "6",
+					Reasons: []string{errors.ErrInvalidSalaryValue.Error()},
+				},
+			},
+		}
+	)
+
+	svc, err := csv.NewParser(givenFilePatterns)
+	assert.NoError(t, err)
+
+	// the employees of each file must be unique only inside the same run, roster7.tsv repeats roster6.csv e-mails.
+	errs := svc.ParseFiles(files[:1])
+	gotEmployees, gotBadData, resultFiles := getResults(t)
+	assert.Empty(t, errs)
+	assert.Equal(t, wantEmployees[:2], gotEmployees)
+	assert.Equal(t, wantBadData, gotBadData)
+	deleteFiles(resultFiles, t)
+
+	errs = svc.ParseFiles(files[1:])
+	gotEmployees, gotBadData, resultFiles = getResults(t)
+	assert.Empty(t, errs)
+	assert.Equal(t, wantEmployees[2:], gotEmployees)
+	assert.Empty(t, gotBadData)
+	deleteFiles(resultFiles, t)
+}
+
+func TestService_ParseFiles_Encoding(t *testing.T) {
+	var (
+		givenPattern = csv.FilePattern{
+			FirstNameColumn: "Name",
+			SalaryColumn:    "Salary",
+			EmailColumn:     "Email",
+			IDColumn:        "ID",
+		}
+
+		wantAccentedEmployees = []*entity.Employee{
+			{
+				ID:     "RT1",
+				Email:  "jose@test.com",
+				Name:   "José Álvarez",
+				Salary: 10,
+			},
+			{
+				ID:     "RT2",
+				Email:  "zoe@test.com",
+				Name:   "Zoë Müller",
+				Salary: 15,
+			},
+		}
+	)
+
+	tt := []struct {
+		name          string
+		givenFile     string
+		givenEncoding string
+		wantEmployees []*entity.Employee
+		wantBadData   map[string][]*csv.BadData
+	}{
+		{
+			name:      "UTF-8 with BOM",
+			givenFile: "test_files/roster8.csv",
+			wantEmployees: []*entity.Employee{
+				{
+					ID:     "RT1",
+					Email:  "doe@test.com",
+					Name:   "John Doe",
+					Salary: 10,
+				},
+				{
+					ID:     "RT2",
+					Email:  "mary@tes.com",
+					Name:   "Mary Jane",
+					Salary: 15,
+				},
+			},
+		},
+		{
+			name:          "Detected UTF-16LE with BOM",
+			givenFile:     "test_files/roster9.csv",
+			wantEmployees: wantAccentedEmployees,
+		},
+		{
+			name:          "Declared UTF-16LE",
+			givenFile:     "test_files/roster9.csv",
+			givenEncoding: csv.EncodingUTF16LE,
+			wantEmployees: wantAccentedEmployees,
+		},
+		{
+			name:          "Detected Windows-1252",
+			givenFile:     "test_files/roster10.csv",
+			wantEmployees: wantAccentedEmployees,
+		},
+		{
+			name:          "Declared Windows-1252",
+			givenFile:     "test_files/roster10.csv",
+			givenEncoding: csv.EncodingWindows1252,
+			wantEmployees: wantAccentedEmployees,
+		},
+		{
+			// the UTF-8 "Á" has the 0x81 byte undefined in Windows-1252, the other lines are read as Windows-1252.
+			name:      "Detected Windows-1252 with undefined bytes",
+			givenFile: "test_files/roster11.csv",
+			wantEmployees: []*entity.Employee{
+				wantAccentedEmployees[1],
+				{
+					ID:     "RT3",
+					Email:  "max@test.com",
+					Name:   "Max Topperson",
+					Salary: 11,
+				},
+			},
+			wantBadData: map[string][]*csv.BadData{
+				"test_files/roster11.csv": {
+					{
+						Line:    "2",
+						Reasons: []string{errors.ErrInvalidEncoding.Error()},
+					},
+				},
+			},
+		},
+		{
+			name:          "Declared UTF-8 with undecodable bytes",
+			givenFile:     "test_files/roster11.csv",
+			givenEncoding: csv.EncodingUTF8,
+			wantEmployees: []*entity.Employee{
+				wantAccentedEmployees[0],
+				{
+					ID:     "RT3",
+					Email:  "max@test.com",
+					Name:   "Max Topperson",
+					Salary: 11,
+				},
+			},
+			wantBadData: map[string][]*csv.BadData{
+				"test_files/roster11.csv": {
+					{
+						Line:    "3",
+						Reasons: []string{errors.ErrInvalidEncoding.Error()},
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			pattern := givenPattern
+			pattern.Encoding = tc.givenEncoding
+
+			svc, err := csv.NewParser(map[string]*csv.FilePattern{tc.givenFile: &pattern})
+			assert.NoError(t, err)
+
+			errs := svc.ParseFiles([]string{tc.givenFile})
+			gotEmployees, gotBadData, files := getResults(t)
+			assert.Empty(t, errs)
+			assert.Equal(t, tc.wantEmployees, gotEmployees)
+			assert.Equal(t, tc.wantBadData, gotBadData)
+			deleteFiles(files, t)
+		})
+	}
+}
+
+func TestService_ParseFiles_MalformedLines(t *testing.T) {
+	var (
+		givenFile    = "test_files/roster12.csv"
+		givenPattern = &csv.FilePattern{
+			FirstNameColumn: "Name",
+			SalaryColumn:    "Wage",
+			EmailColumn:     "Email",
+			IDColumn:        "Number",
+		}
+
+		wantEmployees = []*entity.Employee{
+			{
+				ID:     "1",
+				Email:  "doe@test.com",
+				Name:   "John Doe",
+				Salary: 10,
+			},
+			{
+				ID:     "5",
+				Email:  "bob@test.com",
+				Name:   "Bob Ray",
+				Salary: 8,
+			},
+		}
+
+		wantBadData = map[string][]*csv.BadData{
+			givenFile: {
+				{
+					Line:    "3",
+					Reasons: []string{errors.ErrMalformedLine.Error() + ": wrong number of fields"},
+				},
+				{
+					Line:    "4",
+					Reasons: []string{errors.ErrMalformedLine.Error() + `: bare " in non-quoted-field`},
+				},
+				{
+					Line:    "5",
+					Reasons: []string{errors.ErrMalformedLine.Error() + ": wrong number of fields"},
+				},
+			},
+		}
+	)
+
+	svc, err := csv.NewParser(map[string]*csv.FilePattern{givenFile: givenPattern})
+	assert.NoError(t, err)
+
+	errs := svc.ParseFiles([]string{givenFile})
+	gotEmployees, gotBadData, files := getResults(t)
+	assert.Empty(t, errs)
+	assert.Equal(t, wantEmployees, gotEmployees)
+	assert.Equal(t, wantBadData, gotBadData)
+	assert.Equal(t, 3, svc.Summary().BadLines)
+	deleteFiles(files, t)
+}
+
+func TestService_ParseFiles_XLSX(t *testing.T) {
+	var (
+		givenFile    = "test_files/roster13.xlsx"
+		givenPattern = csv.FilePattern{
+			FirstNameColumn: "Name",
+			SalaryColumn:    "Wage",
+			EmailColumn:     "E-mail",
+			IDColumn:        "Emp Number",
+			Sheet:           &csv.Sheet{Name: "Employees", HeaderRow: 3},
+		}
+
+		wantEmployees = []*entity.Employee{
+			{
+				ID:     "1",
+				Email:  "doe@test.com",
+				Name:   "John Doe",
+				Salary: 10.5,
+			},
+			{
+				ID:     "RT2",
+				Email:  "mary@tes.com",
+				Name:   "Mary Jane",
+				Salary: 15,
+			},
+		}
+
+		// the lines are the sheet rows.
+		wantBadData = map[string][]*csv.BadData{
+			givenFile: {
+				{
+					Line:    "7",
+					Reasons: []string{errors.ErrInvalidEmailFormat.Error()},
+				},
+			},
+		}
+	)
+
+	svc, err := csv.NewParser(map[string]*csv.FilePattern{givenFile: &givenPattern})
+	assert.NoError(t, err)
+
+	errs := svc.ParseFiles([]string{givenFile})
+	gotEmployees, gotBadData, files := getResults(t)
+	assert.Empty(t, errs)
+	assert.Equal(t, wantEmployees, gotEmployees)
+	assert.Equal(t, wantBadData, gotBadData)
+	deleteFiles(files, t)
+
+	givenPattern.Sheet = &csv.Sheet{Name: "Payroll"}
+	svc, err = csv.NewParser(map[string]*csv.FilePattern{givenFile: &givenPattern})
+	assert.NoError(t, err)
+
+	_, errs = svc.Validate([]string{givenFile})
+	assert.ErrorIs(t, errs[givenFile], errors.ErrReadingFile)
+	assert.Contains(t, errs[givenFile].Error(), errors.ErrSheetNotFound.Error())
+}
+
+func TestService_ParseFiles_JSON(t *testing.T) {
+	var (
+		givenPattern = csv.FilePattern{
+			FirstNameColumn: "name.first",
+			LastNameColumn:  "name.last",
+			SalaryColumn:    "salary",
+			EmailColumn:     "contact.email",
+			IDColumn:        "id",
+			PhoneColumn:     "contact.phones.0",
+		}
+
+		wantEmployees = []*entity.Employee{
+			{
+				ID:     "1",
+				Email:  "doe@test.com",
+				Name:   "John Doe",
+				Salary: 10.5,
+				Phone:  "555-0100",
+			},
+			{
+				ID:     "RT2",
+				Email:  "mary@tes.com",
+				Name:   "Mary Jane",
+				Salary: 15,
+			},
+		}
+	)
+
+	tt := []struct {
+		name        string
+		givenFile   string
+		givenFormat csv.Format
+		wantBadData []*csv.BadData
+	}{
+		{
+			name:      "JSON array",
+			givenFile: "test_files/roster14.json",
+			wantBadData: []*csv.BadData{
+				{
+					Line:    "14",
+					Reasons: []string{errors.ErrMalformedLine.Error() + ": a JSON object was expected"},
+				},
+				{
+					Line:    "15",
+					Reasons: []string{errors.ErrInvalidEmailFormat.Error()},
+				},
+			},
+		},
+		{
+			name:        "NDJSON by format",
+			givenFile:   "test_files/roster15.ndjson",
+			givenFormat: csv.FormatNDJSON,
+			wantBadData: []*csv.BadData{
+				{
+					Line:    "4",
+					Reasons: []string{errors.ErrMalformedLine.Error() + ": unexpected EOF"},
+				},
+				{
+					Line:    "5",
+					Reasons: []string{errors.ErrInvalidEmailFormat.Error()},
+				},
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			pattern := givenPattern
+			pattern.Format = tc.givenFormat
+
+			svc, err := csv.NewParser(map[string]*csv.FilePattern{tc.givenFile: &pattern})
+			assert.NoError(t, err)
+
+			errs := svc.ParseFiles([]string{tc.givenFile})
+			gotEmployees, gotBadData, files := getResults(t)
+			assert.Empty(t, errs)
+			assert.Equal(t, wantEmployees, gotEmployees)
+			assert.Equal(t, map[string][]*csv.BadData{tc.givenFile: tc.wantBadData}, gotBadData)
+			deleteFiles(files, t)
+		})
+	}
+}
+
+func TestService_ParseFiles_XML(t *testing.T) {
+	var (
+		givenFile    = "test_files/roster16.xml"
+		givenPattern = &csv.FilePattern{
+			FirstNameColumn: "name/first",
+			LastNameColumn:  "name/last",
+			SalaryColumn:    "salary",
+			EmailColumn:     "contact/email",
+			IDColumn:        "@id",
+			PhoneColumn:     "contact/phone",
+			Element:         "employee",
+		}
+
+		wantEmployees = []*entity.Employee{
+			{
+				ID:     "1",
+				Email:  "doe@test.com",
+				Name:   "John Doe",
+				Salary: 10.5,
+				Phone:  "555-0100",
+			},
+			{
+				ID:     "RT2",
+				Email:  "mary@tes.com",
+				Name:   "Mary Jane",
+				Salary: 15,
+			},
+		}
+
+		// the lines are where each element starts.
+		wantBadData = map[string][]*csv.BadData{
+			givenFile: {
+				{
+					Line:    "23",
+					Reasons: []string{errors.ErrInvalidEmailFormat.Error()},
+				},
+			},
+		}
+	)
+
+	svc, err := csv.NewParser(map[string]*csv.FilePattern{givenFile: givenPattern})
+	assert.NoError(t, err)
+
+	errs := svc.ParseFiles([]string{givenFile})
+	gotEmployees, gotBadData, files := getResults(t)
+	assert.Empty(t, errs)
+	assert.Equal(t, wantEmployees, gotEmployees)
+	assert.Equal(t, wantBadData, gotBadData)
+	deleteFiles(files, t)
+}
+
+func TestService_ParseFiles_FixedWidth(t *testing.T) {
+	var (
+		givenFile    = "test_files/roster17.txt"
+		givenPattern = &csv.FilePattern{
+			FirstNameColumn: "name",
+			SalaryColumn:    "salary",
+			EmailColumn:     "email",
+			IDColumn:        "id",
+			FixedWidth: &csv.FixedWidth{
+				Columns: []*csv.FixedWidthColumn{
+					{Name: "id", Start: 1, Length: 6, Trim: csv.TrimNone},
+					{Name: "name", Start: 7, Length: 20},
+					{Name: "salary", Start: 27, Length: 10},
+					{Name: "email", Start: 37, Length: 20},
+				},
+				SkipLines: 2,
+			},
+		}
+
+		wantEmployees = []*entity.Employee{
+			{
+				ID:     "000001",
+				Email:  "doe@test.com",
+				Name:   "John Doe",
+				Salary: 10.5,
+			},
+			{
+				ID:     "000002",
+				Email:  "mary@tes.com",
+				Name:   "Mary Jane",
+				Salary: 15,
+			},
+		}
+
+		wantBadData = map[string][]*csv.BadData{
+			givenFile: {
+				{
+					Line:    "6",
+					Reasons: []string{errors.ErrShortLine.Error() + ": 33 of 56 chars"},
+				},
+				{
+					Line:    "7",
+					Reasons: []string{errors.ErrInvalidEmailFormat.Error()},
+				},
+			},
+		}
+	)
+
+	svc, err := csv.NewParser(map[string]*csv.FilePattern{givenFile: givenPattern})
+	assert.NoError(t, err)
+
+	errs := svc.ParseFiles([]string{givenFile})
+	gotEmployees, gotBadData, files := getResults(t)
+	assert.Empty(t, errs)
+	assert.Equal(t, wantEmployees, gotEmployees)
+	assert.Equal(t, wantBadData, gotBadData)
+	deleteFiles(files, t)
+}
+
+func TestNewParser_InvalidFixedWidth(t *testing.T) {
+	tt := []struct {
+		name            string
+		givenFormat     csv.Format
+		givenFixedWidth *csv.FixedWidth
+	}{
+		{
+			name:        "Fixed-width format without columns",
+			givenFormat: csv.FormatFixedWidth,
+		},
+		{
+			name: "Column not defined",
+			givenFixedWidth: &csv.FixedWidth{
+				Columns: []*csv.FixedWidthColumn{
+					{Name: "id", Start: 1, Length: 6},
+					{Name: "name", Start: 7, Length: 20},
+				},
+			},
+		},
+		{
+			name: "Invalid start",
+			givenFixedWidth: &csv.FixedWidth{
+				Columns: []*csv.FixedWidthColumn{
+					{Name: "id", Start: 0, Length: 6},
+				},
+			},
+		},
+		{
+			name: "Unknown trim",
+			givenFixedWidth: &csv.FixedWidth{
+				Columns: []*csv.FixedWidthColumn{
+					{Name: "id", Start: 1, Length: 6},
+				},
+				Trim: "middle",
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, err := csv.NewParser(map[string]*csv.FilePattern{
+				"file.txt": {
+					FirstNameColumn: "name",
+					SalaryColumn:    "salary",
+					EmailColumn:     "email",
+					IDColumn:        "id",
+					Format:          tc.givenFormat,
+					FixedWidth:      tc.givenFixedWidth,
+				},
+			})
+			assert.Nil(t, svc)
+			assert.ErrorIs(t, err, errors.ErrInvalidFixedWidth)
+		})
+	}
+}
+
+func TestService_ParseFiles_Archives(t *testing.T) {
+	var (
+		givenPattern = &csv.FilePattern{
+			FirstNameColumn: "Name",
+			SalaryColumn:    "Wage",
+			EmailColumn:     "Email",
+			IDColumn:        "Number",
+		}
+
+		wantAnn = &entity.Employee{
+			ID:     "A1",
+			Email:  "ann@test.com",
+			Name:   "Ann Lee",
+			Salary: 7,
+		}
+		wantBadLine = []*csv.BadData{
+			{
+				Line:    "3",
+				Reasons: []string{errors.ErrInvalidEmailFormat.Error()},
+			},
+		}
+	)
+
+	tt := []struct {
+		name          string
+		givenFile     string
+		givenPatterns map[string]*csv.FilePattern
+		wantEmployees []*entity.Employee
+		wantBadData   map[string][]*csv.BadData
+		wantErrs      []string
+	}{
+		{
+			name:      "Gzip file",
+			givenFile: "test_files/roster20.csv.gz",
+			givenPatterns: map[string]*csv.FilePattern{
+				"test_files/roster20.csv.gz": givenPattern,
+			},
+			wantEmployees: []*entity.Employee{wantAnn},
+			wantBadData:   map[string][]*csv.BadData{"test_files/roster20.csv.gz": wantBadLine},
+		},
+		{
+			name:      "Zip archive with compressed and nested archive members",
+			givenFile: "test_files/roster18.zip",
+			givenPatterns: map[string]*csv.FilePattern{
+				"a.csv":                                  givenPattern,
+				"test_files/roster18.zip!more/b.csv.bz2": givenPattern,
+			},
+			wantEmployees: []*entity.Employee{
+				wantAnn,
+				{
+					ID:     "B1",
+					Email:  "carl@test.com",
+					Name:   "Carl Poe",
+					Salary: 9,
+				},
+			},
+			wantBadData: map[string][]*csv.BadData{"test_files/roster18.zip!a.csv": wantBadLine},
+			wantErrs:    []string{"test_files/roster18.zip!inner.zip"},
+		},
+		{
+			name:      "Zstd tar archive",
+			givenFile: "test_files/roster19.tar.zst",
+			givenPatterns: map[string]*csv.FilePattern{
+				"test_files/roster19.tar.zst!a.csv": givenPattern,
+			},
+			wantEmployees: []*entity.Employee{wantAnn},
+			wantBadData:   map[string][]*csv.BadData{"test_files/roster19.tar.zst!a.csv": wantBadLine},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, err := csv.NewParser(tc.givenPatterns)
+			assert.NoError(t, err)
+
+			errs := svc.ParseFiles([]string{tc.givenFile})
+			gotEmployees, gotBadData, files := getResults(t)
+			assert.Len(t, errs, len(tc.wantErrs))
+			for _, key := range tc.wantErrs {
+				assert.ErrorIs(t, errs[key], errors.ErrReadingFile)
+			}
+			assert.Equal(t, tc.wantEmployees, gotEmployees)
+			assert.Equal(t, tc.wantBadData, gotBadData)
+			deleteFiles(files, t)
+		})
+	}
+}
+
+func TestNewParser_InvalidEncoding(t *testing.T) {
+	var givenFilePatterns = map[string]*csv.FilePattern{
+		"file.csv": {
+			FirstNameColumn: "Name",
+			SalaryColumn:    "Wage",
+			EmailColumn:     "Email",
+			IDColumn:        "Number",
+			Encoding:        "ebcdic",
+		},
+	}
+
+	svc, err := csv.NewParser(givenFilePatterns)
+	assert.Nil(t, svc)
+	assert.ErrorIs(t, err, errors.ErrInvalidEncoding)
+}
+
+func TestNewParser_InvalidDialect(t *testing.T) {
+	var givenFilePatterns = map[string]*csv.FilePattern{
+		"file.csv": {
+			FirstNameColumn: "Name",
+			SalaryColumn:    "Wage",
+			EmailColumn:     "Email",
+			IDColumn:        "Number",
+			Dialect:         &csv.Dialect{Delimiter: "::"},
+		},
+	}
+
+	svc, err := csv.NewParser(givenFilePatterns)
+	assert.Nil(t, svc)
+	assert.ErrorIs(t, err, errors.ErrInvalidDialect)
+}
+
+func TestNewParser_InvalidXLSXPattern(t *testing.T) {
+	tt := []struct {
+		name         string
+		givenPattern *csv.FilePattern
+		wantErr      error
+	}{
+		{
+			name: "Unsupported format",
+			givenPattern: &csv.FilePattern{
+				FirstNameColumn: "Name",
+				SalaryColumn:    "Wage",
+				EmailColumn:     "Email",
+				IDColumn:        "Number",
+				Format:          "ods",
+			},
+			wantErr: errors.ErrUnsupportedFormat,
+		},
+		{
+			name: "Negative header row",
+			givenPattern: &csv.FilePattern{
+				FirstNameColumn: "Name",
+				SalaryColumn:    "Wage",
+				EmailColumn:     "Email",
+				IDColumn:        "Number",
+				Sheet:           &csv.Sheet{HeaderRow: -1},
+			},
+			wantErr: errors.ErrInvalidSheet,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, err := csv.NewParser(map[string]*csv.FilePattern{"file.xlsx": tc.givenPattern})
+			assert.Nil(t, svc)
+			assert.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestService_ParseFiles_GlobPattern(t *testing.T) {
+	givenFilePatterns := map[string]*csv.FilePattern{
+		"roster?.csv": {
+			FirstNameColumn: "Name",
+			SalaryColumn:    "Wage",
+			EmailColumn:     "Email",
+			IDColumn:        "Number",
+		},
+	}
+
+	svc, err := csv.NewParser(givenFilePatterns)
+	assert.NoError(t, err)
+
+	errs := svc.ParseFiles([]string{"test_files/roster1.csv", "test_files/roster12.csv"})
+	gotEmployees, _, files := getResults(t)
+	assert.Len(t, errs, 1)
+	assert.ErrorIs(t, errs["test_files/roster12.csv"], errors.ErrUnprocessableFile)
+	assert.Len(t, gotEmployees, 3)
+	deleteFiles(files, t)
+
+	svc, err = csv.NewParser(map[string]*csv.FilePattern{"roster[.csv": givenFilePatterns["roster?.csv"]})
+	assert.Nil(t, svc)
+	assert.ErrorIs(t, err, errors.ErrInvalidGlob)
+}
+
 func TestService_ParseFiles_Error(t *testing.T) {
 	var (
 		givenFilePatterns = map[string]*csv.FilePattern{
-			"file.csv": {
+			"file.csv": {
+				FirstNameColumn: "Name",
+				SalaryColumn:    "Wage",
+				EmailColumn:     "Email",
+				IDColumn:        "Number",
+			},
+		}
+		givenFiles = []string{"not_found.csv", "test_files/bad_file.csv", "test_files/roster1.csv"}
+	)
+
+	svc, err := csv.NewParser(givenFilePatterns)
+	assert.NotNil(t, svc)
+	assert.NoError(t, err)
+
+	errs := svc.ParseFiles(givenFiles)
+	assert.ErrorIs(t, errs["not_found.csv"], errors.ErrOpeningFile)
+	assert.ErrorIs(t, errs["test_files/bad_file.csv"], errors.ErrReadingFile)
+	assert.ErrorIs(t, errs["test_files/roster1.csv"], errors.ErrUnprocessableFile)
+
+	var fileErr *errors.FileError
+	if assert.ErrorAs(t, errs["not_found.csv"], &fileErr) {
+		assert.Equal(t, "not_found.csv", fileErr.Path)
+		assert.Equal(t, errors.OpOpen, fileErr.Op)
+	}
+	if assert.ErrorAs(t, errs["test_files/bad_file.csv"], &fileErr) {
+		assert.Equal(t, errors.OpRead, fileErr.Op)
+	}
+	if assert.ErrorAs(t, errs["test_files/roster1.csv"], &fileErr) {
+		assert.Equal(t, errors.OpFindPattern, fileErr.Op)
+	}
+}
+
+func TestNewParser_InvalidPolicy(t *testing.T) {
+	var givenFilePatterns = map[string]*csv.FilePattern{
+		"file.csv": {
+			FirstNameColumn: "Name",
+			SalaryColumn:    "Wage",
+			EmailColumn:     "Email",
+			IDColumn:        "Number",
+		},
+	}
+
+	tt := []struct {
+		name        string
+		givenPolicy csv.Policy
+	}{
+		{
+			name:        "Negative MaxBadLines",
+			givenPolicy: csv.Policy{MaxBadLines: -1},
+		},
+		{
+			name:        "MaxBadRatio greater than 1",
+			givenPolicy: csv.Policy{MaxBadRatio: 1.5},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, err := csv.NewParser(givenFilePatterns, csv.WithPolicy(tc.givenPolicy))
+			assert.Nil(t, svc)
+			assert.ErrorIs(t, err, errors.ErrInvalidPolicy)
+		})
+	}
+}
+
+func TestService_ParseFiles_FailFast(t *testing.T) {
+	var (
+		givenFilePatterns = map[string]*csv.FilePattern{
+			"test_files/roster2.csv": {
+				FirstNameColumn: "First",
+				LastNameColumn:  "Last",
+				SalaryColumn:    "Salary",
+				EmailColumn:     "E-mail",
+				IDColumn:        "ID",
+			},
+			"test_files/roster1.csv": {
+				FirstNameColumn: "Name",
+				SalaryColumn:    "Wage",
+				EmailColumn:     "Email",
+				IDColumn:        "Number",
+			},
+			"test_files/roster3.csv": {
+				FirstNameColumn: "first name",
+				LastNameColumn:  "last name",
+				SalaryColumn:    "Rate",
+				EmailColumn:     "e-mail",
+				IDColumn:        "Employee Number",
+			},
+		}
+		givenFiles = []string{"test_files/roster2.csv", "test_files/roster1.csv", "test_files/roster3.csv"}
+
+		wantBadData = map[string][]*csv.BadData{
+			"test_files/roster1.csv": {
+				{
+					Line:    "2",
+					Reasons: []string{errors.ErrEmailConstraintViolation.Error()},
+				},
+			},
+		}
+	)
+
+	svc, err := csv.NewParser(givenFilePatterns, csv.WithPolicy(csv.Policy{FailFast: true}))
+	assert.NoError(t, err)
+
+	errs := svc.ParseFiles(givenFiles)
+	gotEmployees, gotBadData, files := getResults(t)
+	assert.Len(t, gotEmployees, 5)
+	assert.Equal(t, wantBadData, gotBadData)
+	assert.Len(t, errs, 2)
+	assert.ErrorIs(t, errs["test_files/roster1.csv"], errors.ErrFailFast)
+	assert.ErrorIs(t, errs["test_files/roster3.csv"], errors.ErrRunAborted)
+	deleteFiles(files, t)
+}
+
+func TestService_ParseFiles_MaxBadRatio(t *testing.T) {
+	var (
+		givenFilePatterns = map[string]*csv.FilePattern{
+			"test_files/roster3.csv": {
+				FirstNameColumn: "first name",
+				LastNameColumn:  "last name",
+				SalaryColumn:    "Rate",
+				EmailColumn:     "e-mail",
+				IDColumn:        "Employee Number",
+			},
+			"test_files/roster4.csv": {
+				FirstNameColumn: "f. name",
+				LastNameColumn:  "l. name",
+				SalaryColumn:    "wage",
+				EmailColumn:     "email",
+				IDColumn:        "emp id",
+			},
+		}
+		givenFiles = []string{"test_files/roster3.csv", "test_files/roster4.csv"}
+
+		// the employees from the rejected roster3.csv must not block the same IDs and e-mails from roster4.csv.
+		wantEmployees = []*entity.Employee{
+			{
+				ID:     "RT2",
+				Email:  "mary@tes.com",
+				Name:   "Mary Jane",
+				Salary: 15,
+			},
+			{
+				ID:     "RT4",
+				Email:  "alfred@test.com",
+				Name:   "Alfred Donald",
+				Salary: 11.5,
+			},
+			{
+				ID:     "RT5",
+				Email:  "jane.doe@test.com",
+				Name:   "Jane Doe",
+				Salary: 8.45,
+			},
+		}
+	)
+
+	svc, err := csv.NewParser(givenFilePatterns, csv.WithPolicy(csv.Policy{MaxBadRatio: 0.55}))
+	assert.NoError(t, err)
+
+	errs := svc.ParseFiles(givenFiles)
+	gotEmployees, gotBadData, files := getResults(t)
+	assert.Equal(t, wantEmployees, gotEmployees)
+	assert.Len(t, gotBadData, 2)
+	assert.Len(t, errs, 1)
+	assert.ErrorIs(t, errs["test_files/roster3.csv"], errors.ErrBadDataThreshold)
+	deleteFiles(files, t)
+}
+
+func TestService_ParseFiles_MaxBadLines(t *testing.T) {
+	var (
+		givenFile         = "test_files/roster5.csv"
+		givenFilePatterns = map[string]*csv.FilePattern{
+			givenFile: {
+				FirstNameColumn: "f. name",
+				LastNameColumn:  "l. name",
+				SalaryColumn:    "wage",
+				EmailColumn:     "email",
+				IDColumn:        "emp id",
+			},
+		}
+	)
+
+	svc, err := csv.NewParser(givenFilePatterns, csv.WithPolicy(csv.Policy{MaxBadLines: 3}))
+	assert.NoError(t, err)
+
+	errs := svc.ParseFiles([]string{givenFile})
+	gotEmployees, gotBadData, files := getResults(t)
+	assert.Empty(t, gotEmployees)
+	assert.Len(t, gotBadData[givenFile], 4)
+	assert.ErrorIs(t, errs[givenFile], errors.ErrBadDataThreshold)
+	deleteFiles(files, t)
+}
+
+func TestService_ParseFiles_AllOrNothing(t *testing.T) {
+	var (
+		givenFilePatterns = map[string]*csv.FilePattern{
+			"test_files/roster2.csv": {
+				FirstNameColumn: "First",
+				LastNameColumn:  "Last",
+				SalaryColumn:    "Salary",
+				EmailColumn:     "E-mail",
+				IDColumn:        "ID",
+			},
+		}
+		givenFiles = []string{"test_files/roster2.csv", "not_found.csv"}
+	)
+
+	svc, err := csv.NewParser(givenFilePatterns, csv.WithPolicy(csv.Policy{AllOrNothing: true}))
+	assert.NoError(t, err)
+
+	errs := svc.ParseFiles(givenFiles)
+	gotEmployees, _, files := getResults(t)
+	assert.Empty(t, gotEmployees)
+	assert.ErrorIs(t, errs["not_found.csv"], errors.ErrOpeningFile)
+	assert.ErrorIs(t, errs["writeEmployeesFile"], errors.ErrAllOrNothing)
+	deleteFiles(files, t)
+}
+
+func TestService_ParseFiles_WriteError(t *testing.T) {
+	var (
+		givenFile         = "test_files/roster1.csv"
+		givenFilePatterns = map[string]*csv.FilePattern{
+			givenFile: {
 				FirstNameColumn: "Name",
 				SalaryColumn:    "Wage",
 				EmailColumn:     "Email",
 				IDColumn:        "Number",
 			},
 		}
-		givenFiles = []string{"not_found.csv", "test_files/bad_file.csv", "test_files/roster1.csv"}
+	)
+
+	// the output dir is below a file, so it can not be created.
+	parent, err := ioutil.TempFile("", "output")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent.Close()
+	defer os.Remove(parent.Name())
+	givenOutputDir := filepath.Join(parent.Name(), "results")
+
+	svc, err := csv.NewParser(givenFilePatterns, csv.WithOutputDir(givenOutputDir))
+	assert.NoError(t, err)
+
+	errs := svc.ParseFiles([]string{givenFile})
+	for key, prefix := range map[string]string{"writeEmployeesFile": "employee-", "writeBadDataFile": "badData-"} {
+		var fileErr *errors.FileError
+		if assert.ErrorAs(t, errs[key], &fileErr, key) {
+			assert.Equal(t, givenOutputDir, filepath.Dir(fileErr.Path))
+			assert.Regexp(t, "^"+prefix+`\d+\.json$`, filepath.Base(fileErr.Path))
+			assert.ErrorIs(t, fileErr, errors.ErrWriteFile)
+		}
+	}
+	assert.Empty(t, svc.Summary().EmployeesFile)
+	assert.Empty(t, svc.Summary().BadDataFile)
+}
+
+func TestService_ParseFiles_Progress(t *testing.T) {
+	var (
+		givenFile         = "test_files/roster1.csv"
+		givenFilePatterns = map[string]*csv.FilePattern{
+			givenFile: {
+				FirstNameColumn: "Name",
+				SalaryColumn:    "Wage",
+				EmailColumn:     "Email",
+				IDColumn:        "Number",
+			},
+		}
+		got []csv.Progress
+	)
+
+	svc, err := csv.NewParser(givenFilePatterns, csv.WithProgress(func(progress csv.Progress) {
+		got = append(got, progress)
+	}))
+	assert.NoError(t, err)
+
+	errs := svc.ParseFiles([]string{givenFile})
+	_, _, files := getResults(t)
+	assert.Empty(t, errs)
+	if assert.Len(t, got, 5) {
+		assert.Equal(t, csv.Progress{Rows: 1, Accepted: 1}, got[0])
+		assert.Equal(t, csv.Progress{Rows: 5, Accepted: 3, Rejected: 2}, got[4])
+	}
+	deleteFiles(files, t)
+}
+
+func TestService_ParseFiles_Cancelled(t *testing.T) {
+	var (
+		givenFilePatterns = map[string]*csv.FilePattern{
+			"test_files/roster1.csv": {
+				FirstNameColumn: "Name",
+				SalaryColumn:    "Wage",
+				EmailColumn:     "Email",
+				IDColumn:        "Number",
+			},
+		}
+		givenFiles = []string{"test_files/roster1.csv", "test_files/roster2.csv"}
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	svc, err := csv.NewParser(givenFilePatterns, csv.WithContext(ctx), csv.WithProgress(func(progress csv.Progress) {
+		if progress.Rows == 2 {
+			cancel()
+		}
+	}))
+	assert.NoError(t, err)
+
+	errs := svc.ParseFiles(givenFiles)
+	assert.Len(t, errs, 2)
+	assert.ErrorIs(t, errs["test_files/roster1.csv"], errors.ErrRunCancelled)
+	assert.ErrorIs(t, errs["test_files/roster2.csv"], errors.ErrRunAborted)
+
+	got := svc.Summary()
+	if assert.NotNil(t, got) {
+		assert.Empty(t, got.EmployeesFile)
+		assert.Empty(t, got.BadDataFile)
+	}
+}
+
+type sinkFile struct {
+	file      string
+	employees int
+	badLines  int
+}
+
+type fakeSink struct {
+	files []sinkFile
+	err   error
+}
+
+func (f *fakeSink) WriteFile(file string, employees []*entity.Employee, badData []*csv.BadData) error {
+	f.files = append(f.files, sinkFile{file: file, employees: len(employees), badLines: len(badData)})
+	return f.err
+}
+
+func TestService_ParseFiles_Sink(t *testing.T) {
+	var (
+		givenFilePatterns = map[string]*csv.FilePattern{
+			"test_files/roster1.csv": {
+				FirstNameColumn: "Name",
+				SalaryColumn:    "Wage",
+				EmailColumn:     "Email",
+				IDColumn:        "Number",
+			},
+			"test_files/roster5.csv": {
+				FirstNameColumn: "f. name",
+				LastNameColumn:  "l. name",
+				SalaryColumn:    "wage",
+				EmailColumn:     "email",
+				IDColumn:        "emp id",
+			},
+		}
+		givenFiles = []string{"test_files/roster1.csv", "not_found.csv", "test_files/roster5.csv"}
+
+		// the employees of roster5.csv are not sent since it is rejected by the threshold.
+		want = []sinkFile{
+			{file: "test_files/roster1.csv", employees: 3, badLines: 2},
+			{file: "test_files/roster5.csv", badLines: 4},
+		}
+	)
+
+	sink := &fakeSink{}
+	svc, err := csv.NewParser(givenFilePatterns, csv.WithPolicy(csv.Policy{MaxBadLines: 3}), csv.WithSink(sink))
+	assert.NoError(t, err)
+
+	errs := svc.ParseFiles(givenFiles)
+	_, _, files := getResults(t)
+	assert.Len(t, errs, 2)
+	assert.Equal(t, want, sink.files)
+	deleteFiles(files, t)
+}
+
+func TestService_ParseFiles_SinkError(t *testing.T) {
+	var (
+		givenFile         = "test_files/roster1.csv"
+		givenFilePatterns = map[string]*csv.FilePattern{
+			givenFile: {
+				FirstNameColumn: "Name",
+				SalaryColumn:    "Wage",
+				EmailColumn:     "Email",
+				IDColumn:        "Number",
+			},
+		}
+	)
+
+	svc, err := csv.NewParser(givenFilePatterns, csv.WithSink(&fakeSink{err: errors.ErrWriteFile}))
+	assert.NoError(t, err)
+
+	errs := svc.ParseFiles([]string{givenFile})
+	_, _, files := getResults(t)
+	assert.Len(t, errs, 1)
+	assert.ErrorIs(t, errs["writeSink:"+givenFile], errors.ErrWriteSink)
+	assert.Equal(t, 3, svc.Summary().Employees)
+	deleteFiles(files, t)
+}
+
+func TestService_Summary(t *testing.T) {
+	var (
+		givenFilePatterns = map[string]*csv.FilePattern{
+			"test_files/roster1.csv": {
+				FirstNameColumn: "Name",
+				SalaryColumn:    "Wage",
+				EmailColumn:     "Email",
+				IDColumn:        "Number",
+			},
+		}
+		givenFiles = []string{"test_files/roster1.csv", "not_found.csv"}
 	)
 
 	svc, err := csv.NewParser(givenFilePatterns)
-	assert.NotNil(t, svc)
 	assert.NoError(t, err)
+	assert.Nil(t, svc.Summary())
 
 	errs := svc.ParseFiles(givenFiles)
+	_, _, files := getResults(t)
+
+	got := svc.Summary()
+	if assert.NotNil(t, got) {
+		assert.Equal(t, 2, got.Files)
+		assert.Equal(t, 3, got.Employees)
+		assert.Equal(t, 2, got.BadLines)
+		assert.Equal(t, map[string]string{"not_found.csv": errs["not_found.csv"].Error()}, got.Errors)
+		assert.ElementsMatch(t, files, []string{got.EmployeesFile, got.BadDataFile})
+	}
+	deleteFiles(files, t)
+}
+
+func TestService_Validate(t *testing.T) {
+	var (
+		givenFile         = "test_files/roster5.csv"
+		givenFilePatterns = map[string]*csv.FilePattern{
+			givenFile: {
+				FirstNameColumn: "f. name",
+				LastNameColumn:  "l. name",
+				SalaryColumn:    "wage",
+				EmailColumn:     "email",
+				IDColumn:        "emp id",
+				PhoneColumn:     "phone",
+			},
+		}
+
+		wantReasons = map[string]int{
+			errors.ErrEmptyName.Error():                2,
+			errors.ErrInvalidSalaryValue.Error():       2,
+			errors.ErrInvalidEmailFormat.Error():       2,
+			errors.ErrInvalidIDValue.Error():           1,
+			errors.ErrEmailConstraintViolation.Error(): 1,
+			errors.ErrIDConstraintViolation.Error():    1,
+		}
+		wantColumns = map[string]int{
+			"f. name": 2,
+			"wage":    2,
+			"email":   3,
+			"emp id":  2,
+		}
+	)
+
+	svc, err := csv.NewParser(givenFilePatterns, csv.WithReportSamples(2))
+	assert.NoError(t, err)
+
+	report, errs := svc.Validate([]string{givenFile, "not_found.csv"})
+	gotEmployees, gotBadData, files := getResults(t)
+	assert.Empty(t, files)
+	assert.Empty(t, gotEmployees)
+	assert.Empty(t, gotBadData)
 	assert.ErrorIs(t, errs["not_found.csv"], errors.ErrOpeningFile)
-	assert.ErrorIs(t, errs["test_files/bad_file.csv"], errors.ErrReadingFile)
-	assert.ErrorIs(t, errs["test_files/roster1.csv"], errors.ErrUnprocessableFile)
+
+	assert.Equal(t, svc.Summary(), report.Summary)
+	assert.Equal(t, 1, report.Summary.Employees)
+	assert.Equal(t, 4, report.Summary.BadLines)
+	assert.Equal(t, wantReasons, report.Reasons)
+
+	fileReport := report.Files[givenFile]
+	if assert.NotNil(t, fileReport) {
+		assert.Equal(t, 5, fileReport.Lines)
+		assert.Equal(t, 4, fileReport.BadLines)
+		assert.Equal(t, wantReasons, fileReport.Reasons)
+		assert.Equal(t, wantColumns, fileReport.Columns)
+		assert.Len(t, fileReport.BadData, 4)
+		if assert.Len(t, fileReport.Samples, 2) {
+			assert.Equal(t, json.Number("2"), fileReport.Samples[0].Line)
+			assert.Equal(t, json.Number("3"), fileReport.Samples[1].Line)
+		}
+	}
+}
+
+func TestService_Validate_DoesNotAffectNextRun(t *testing.T) {
+	var (
+		givenFile         = "test_files/roster2.csv"
+		givenFilePatterns = map[string]*csv.FilePattern{
+			givenFile: {
+				FirstNameColumn: "First",
+				LastNameColumn:  "Last",
+				SalaryColumn:    "Salary",
+				EmailColumn:     "E-mail",
+				IDColumn:        "ID",
+			},
+		}
+	)
+
+	svc, err := csv.NewParser(givenFilePatterns)
+	assert.NoError(t, err)
+
+	report, errs := svc.Validate([]string{givenFile})
+	assert.Empty(t, errs)
+	assert.Equal(t, 0, report.Files[givenFile].BadLines)
+
+	errs = svc.ParseFiles([]string{givenFile})
+	gotEmployees, gotBadData, files := getResults(t)
+	assert.Empty(t, errs)
+	assert.Len(t, gotEmployees, 5)
+	assert.Empty(t, gotBadData)
+	deleteFiles(files, t)
 }
 
 func getResults(t *testing.T) (employees []*entity.Employee, badData map[string][]*csv.BadData, files []string) {
@@ -0,0 +1,102 @@
+package csv
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vsantosalmeida/csv-parser/entity"
+	errs "github.com/vsantosalmeida/csv-parser/pkg/errors"
+)
+
+// writeSink prefixes the key of the errors returned by a Sink in the errors map of ParseFiles.
+const writeSink = "writeSink"
+
+// Sink receives the results of each processed file from Parser.ParseFiles, after the result files
+// are written, like a database or a message queue.
+type Sink interface {
+	// WriteFile receives the employees and bad data of a file, in the order the files were processed.
+	// It is called for every file read to the end, also without employees and bad data, so the sink can
+	// replace the results of a previous run of the file.
+	//
+	// The employees are empty when the file was rejected by the run Policy, or when Policy.AllOrNothing
+	// skipped the employees of the run. With WithMerge each merged employee is received once, with the
+	// first file of its ID.
+	WriteFile(file string, employees []*entity.Employee, badData []*BadData) error
+}
+
+// SummarySink is a Sink also receiving the Summary of the run, after the files were sent to WriteFile.
+type SummarySink interface {
+	Sink
+
+	WriteSummary(summary *Summary) error
+}
+
+// RunSink is a Sink also receiving the ID of the run before the files of each ParseFiles call, so its output
+// can be linked to the entity.Provenance of the employees. The ID is empty unless WithProvenance is set.
+type RunSink interface {
+	Sink
+
+	StartRun(runID string)
+}
+
+// WithSink adds a Sink to the Parser, the sinks receive the files in the order they were added.
+// A SummarySink also receives the Summary of each ParseFiles call, and a RunSink the ID of the run.
+func WithSink(sink Sink) Option {
+	return func(s *service) {
+		s.sinks = append(s.sinks, sink)
+	}
+}
+
+// writeSinks sends the employees and bad data of each read file to the sinks, a failure is added to the errors
+// by the "writeSink:<file>" key and does not stop the other files or sinks.
+func (s *service) writeSinks(result *runResult, skipEmployees bool) {
+	for _, sink := range s.sinks {
+		if runSink, ok := sink.(RunSink); ok {
+			runSink.StartRun(s.runID)
+		}
+	}
+
+	for _, file := range result.files {
+		var employees []*entity.Employee
+		if !skipEmployees {
+			employees = result.fileEmployees[file]
+		}
+		badData := result.badData[file]
+
+		for _, sink := range s.sinks {
+			if err := sink.WriteFile(file, employees, badData); err != nil {
+				log.WithFields(log.Fields{
+					"event":  "write_sink_failed",
+					"file":   file,
+					"reason": err,
+				}).Error("could not write the results of the file to the sink")
+				key := fmt.Sprintf("%s:%s", writeSink, file)
+				result.errors[key] = &errs.FileError{Path: file, Op: errs.OpWrite, Err: errs.NewError(errs.ErrWriteSink, err.Error())}
+			}
+		}
+	}
+}
+
+// writeSinkSummaries sends the Summary to the sinks implementing SummarySink, a failure is added to the errors
+// and to the Summary by the "writeSink:summary" key.
+func (s *service) writeSinkSummaries(errors map[string]error) {
+	for _, sink := range s.sinks {
+		summarySink, ok := sink.(SummarySink)
+		if !ok {
+			continue
+		}
+
+		if err := summarySink.WriteSummary(s.summary); err != nil {
+			log.WithFields(log.Fields{
+				"event":  "write_sink_summary_failed",
+				"reason": err,
+			}).Error("could not write the summary to the sink")
+			key := fmt.Sprintf("%s:summary", writeSink)
+			errors[key] = &errs.FileError{Path: key, Op: errs.OpWrite, Err: errs.NewError(errs.ErrWriteSink, err.Error())}
+			if s.summary.Errors == nil {
+				s.summary.Errors = make(map[string]string)
+			}
+			s.summary.Errors[key] = errors[key].Error()
+		}
+	}
+}
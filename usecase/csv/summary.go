@@ -0,0 +1,54 @@
+package csv
+
+// Summary holds the counters and the result files of a Parser.ParseFiles run.
+type Summary struct {
+	// RunID identifies the run in the entity.Provenance of the employees, only set by WithProvenance.
+	RunID string `json:"run_id,omitempty"`
+	// Files is the number of received files.
+	Files int `json:"files"`
+	// Employees is the number of employees written to the EmployeesFile.
+	Employees int `json:"employees"`
+	// BadLines is the number of lines written to the BadDataFile.
+	BadLines int `json:"bad_lines"`
+	// Errors holds the messages from the errors map returned by ParseFiles.
+	Errors        map[string]string `json:"errors,omitempty"`
+	EmployeesFile string            `json:"employees_file,omitempty"`
+	BadDataFile   string            `json:"bad_data_file,omitempty"`
+	// ProvenanceFile holds the entity.Provenance of the employees with ProvenanceSidecar.
+	ProvenanceFile string `json:"provenance_file,omitempty"`
+	// Merged is the number of employees merged from several files by WithMerge, with their Lineage
+	// in the LineageFile.
+	Merged      int    `json:"merged,omitempty"`
+	LineageFile string `json:"lineage_file,omitempty"`
+	// Diff counts the changes from the previous snapshot, only set by WithDiff.
+	Diff *DiffSummary `json:"diff,omitempty"`
+}
+
+// DiffSummary holds the counters of a Diff and its result file.
+type DiffSummary struct {
+	Added     int    `json:"added"`
+	Removed   int    `json:"removed"`
+	Changed   int    `json:"changed"`
+	Unchanged int    `json:"unchanged"`
+	File      string `json:"file"`
+}
+
+func newSummary(files []string, employees int, badData map[string][]*BadData, errors map[string]error) *Summary {
+	summary := &Summary{
+		Files:     len(files),
+		Employees: employees,
+	}
+
+	for _, lines := range badData {
+		summary.BadLines += len(lines)
+	}
+
+	if len(errors) != 0 {
+		summary.Errors = make(map[string]string, len(errors))
+		for k, v := range errors {
+			summary.Errors[k] = v.Error()
+		}
+	}
+
+	return summary
+}
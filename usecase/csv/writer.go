@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -12,7 +14,34 @@ import (
 
 const filenamePrefix = "%s-%s.json"
 
-func (s *service) writeEmployeesResultFile(employees []*entity.Employee) error {
+// WithOutputDir sets the directory of the result files written by Parser.ParseFiles, created when it does
+// not exist. The files are written to the working directory by default.
+func WithOutputDir(dir string) Option {
+	return func(s *service) {
+		s.outputDir = dir
+	}
+}
+
+// resultFileName returns the path of a result file in the output directory.
+func (s *service) resultFileName(name string) string {
+	return filepath.Join(s.outputDir, fmt.Sprintf(filenamePrefix, name, time.Now().Format("20060102150405")))
+}
+
+// writeResultFile writes the result file, creating the output directory.
+func (s *service) writeResultFile(fileName string, data []byte) error {
+	if s.outputDir != "" {
+		if err := os.MkdirAll(s.outputDir, 0755); err != nil {
+			return err
+		}
+	}
+
+	return ioutil.WriteFile(fileName, data, 0644)
+}
+
+// writeEmployeesResultFile writes the employees, the path of the file is also returned when it could not be
+// written, like by the other write*ResultFile methods, so the failure reports the file.
+func (s *service) writeEmployeesResultFile(employees []*entity.Employee) (string, error) {
+	var fileName string
 	if len(employees) > 0 {
 		file, err := json.MarshalIndent(employees, "", " ")
 		if err != nil {
@@ -20,27 +49,30 @@ func (s *service) writeEmployeesResultFile(employees []*entity.Employee) error {
 				"event":  "marshal_employees_failed",
 				"reason": err,
 			}).Error("could not parse Employee to a json structure")
-			return err
+			return "", err
 		}
 
-		err = ioutil.WriteFile(fmt.Sprintf(filenamePrefix, "employee", time.Now().Format("20060102150405")), file, 0644)
-		if err != nil {
+		fileName = s.resultFileName("employee")
+		if err := s.writeResultFile(fileName, file); err != nil {
 			log.WithFields(log.Fields{
 				"event":  "write_employee_file_failed",
+				"file":   fileName,
 				"reason": err,
 			}).Error()
-			return err
+			return fileName, err
 		}
 
 		log.WithFields(log.Fields{
 			"event": "employee_result_file_wrote",
+			"file":  fileName,
 		}).Info()
 	}
 
-	return nil
+	return fileName, nil
 }
 
-func (s *service) writeBadDataResultFile(badData map[string][]*BadData) error {
+func (s *service) writeBadDataResultFile(badData map[string][]*BadData) (string, error) {
+	var fileName string
 	if len(badData) > 0 {
 		file, err := json.MarshalIndent(badData, "", " ")
 		if err != nil {
@@ -48,21 +80,24 @@ func (s *service) writeBadDataResultFile(badData map[string][]*BadData) error {
 				"event":  "marshal_bad_data_failed",
 				"reason": err,
 			}).Error("could not parse BadData to a json structure")
-			return err
+			return "", err
 		}
-		err = ioutil.WriteFile(fmt.Sprintf(filenamePrefix, "badData", time.Now().Format("20060102150405")), file, 0644)
-		if err != nil {
+
+		fileName = s.resultFileName("badData")
+		if err := s.writeResultFile(fileName, file); err != nil {
 			log.WithFields(log.Fields{
 				"event":  "write_bad_data_file_failed",
+				"file":   fileName,
 				"reason": err,
 			}).Error()
-			return err
+			return fileName, err
 		}
 
 		log.WithFields(log.Fields{
 			"event": "bad_data_result_file_wrote",
+			"file":  fileName,
 		}).Info()
 	}
 
-	return nil
+	return fileName, nil
 }
@@ -37,7 +37,7 @@ func TestService_writeEmployeesResultFile(t *testing.T) {
 	)
 
 	svc := service{}
-	err := svc.writeEmployeesResultFile(givenEmployees)
+	fileName, err := svc.writeEmployeesResultFile(givenEmployees)
 	assert.NoError(t, err)
 
 	eMatches, err := filepath.Glob(employeePattern)
@@ -45,6 +45,7 @@ func TestService_writeEmployeesResultFile(t *testing.T) {
 		t.FailNow()
 	}
 	assert.NotEmpty(t, eMatches)
+	assert.Equal(t, eMatches[0], fileName)
 	deleteFile(eMatches[0], t)
 }
 
@@ -74,7 +75,7 @@ func TestService_writeBadDataResultFile(t *testing.T) {
 	)
 
 	svc := service{}
-	err := svc.writeBadDataResultFile(givenBadData)
+	fileName, err := svc.writeBadDataResultFile(givenBadData)
 	assert.NoError(t, err)
 
 	eMatches, err := filepath.Glob(badDataPattern)
@@ -82,6 +83,7 @@ func TestService_writeBadDataResultFile(t *testing.T) {
 		t.FailNow()
 	}
 	assert.NotEmpty(t, eMatches)
+	assert.Equal(t, eMatches[0], fileName)
 	deleteFile(eMatches[0], t)
 }
 
@@ -111,8 +113,9 @@ func TestService_writeEmployeesResultFile_JsonError(t *testing.T) {
 	)
 
 	svc := service{}
-	err := svc.writeEmployeesResultFile(givenEmployees)
+	fileName, err := svc.writeEmployeesResultFile(givenEmployees)
 	assert.Error(t, err)
+	assert.Empty(t, fileName)
 
 	eMatches, err := filepath.Glob(employeePattern)
 	if err != nil {
@@ -147,8 +150,9 @@ func TestService_writeBadDataResultFile_JsonError(t *testing.T) {
 	)
 
 	svc := service{}
-	err := svc.writeBadDataResultFile(givenBadData)
+	fileName, err := svc.writeBadDataResultFile(givenBadData)
 	assert.Error(t, err)
+	assert.Empty(t, fileName)
 
 	eMatches, err := filepath.Glob(badDataPattern)
 	if err != nil {
@@ -0,0 +1,478 @@
+package csv
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	errs "github.com/vsantosalmeida/csv-parser/pkg/errors"
+)
+
+const (
+	xlsxWorkbook      = "xl/workbook.xml"
+	xlsxWorkbookRels  = "xl/_rels/workbook.xml.rels"
+	xlsxSharedStrings = "xl/sharedStrings.xml"
+	xlsxStyles        = "xl/styles.xml"
+)
+
+// Sheet configures how a XLSX workbook is read, the zero value reads the first sheet with the header in the first row.
+type Sheet struct {
+	// Name of the sheet to read, the first sheet of the workbook when empty.
+	Name string `json:"name,omitempty"`
+	// HeaderRow is the number of the row with the columns' names, the rows above it are ignored. 1 when 0.
+	HeaderRow int `json:"header_row,omitempty"`
+}
+
+func validateSheet(s *Sheet) error {
+	if s == nil {
+		return nil
+	}
+
+	if s.HeaderRow < 0 {
+		return errs.NewError(errs.ErrInvalidSheet, "the header row must be greater than 0")
+	}
+
+	return nil
+}
+
+type xlsxRichText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t *xlsxRichText) String() string {
+	if t == nil {
+		return ""
+	}
+
+	s := t.Text
+	for _, run := range t.Runs {
+		s += run.Text
+	}
+
+	return s
+}
+
+type xlsxCell struct {
+	Ref    string        `xml:"r,attr"`
+	Type   string        `xml:"t,attr"`
+	Style  int           `xml:"s,attr"`
+	Value  string        `xml:"v"`
+	Inline *xlsxRichText `xml:"is"`
+}
+
+type xlsxRow struct {
+	Number int        `xml:"r,attr"`
+	Cells  []xlsxCell `xml:"c"`
+}
+
+// xlsxRecordReader is a recordReader for a sheet of a XLSX workbook, the sheet rows are read one by one
+// and each record is returned with its row number as the line.
+type xlsxRecordReader struct {
+	// sheet is the open worksheet entry read by the decoder.
+	sheet         io.ReadCloser
+	decoder       *xml.Decoder
+	sharedStrings []string
+	// dateStyles holds the cell styles with a date number format.
+	dateStyles map[int]bool
+	date1904   bool
+	headerRow  int
+	// columns is the number of columns of the header, the shorter rows are filled with empty fields.
+	columns int
+	lastRow int
+}
+
+// newXLSXRecordReader reads the workbook from r and returns a reader of the Sheet rows.
+func newXLSXRecordReader(r io.Reader, sheet *Sheet) (*xlsxRecordReader, error) {
+	if sheet == nil {
+		sheet = &Sheet{}
+	}
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		entries[f.Name] = f
+	}
+
+	sheetFile, date1904, err := findSheet(entries, sheet.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := &xlsxRecordReader{
+		date1904:  date1904,
+		headerRow: sheet.HeaderRow,
+	}
+
+	if f, ok := entries[xlsxSharedStrings]; ok {
+		if reader.sharedStrings, err = readSharedStrings(f); err != nil {
+			return nil, err
+		}
+	}
+
+	if f, ok := entries[xlsxStyles]; ok {
+		if reader.dateStyles, err = readDateStyles(f); err != nil {
+			return nil, err
+		}
+	}
+
+	rc, err := sheetFile.Open()
+	if err != nil {
+		return nil, err
+	}
+	reader.sheet = rc
+	reader.decoder = xml.NewDecoder(rc)
+
+	return reader, nil
+}
+
+// Close closes the worksheet entry of the workbook.
+func (r *xlsxRecordReader) Close() error {
+	return r.sheet.Close()
+}
+
+func (r *xlsxRecordReader) Read() ([]string, int, error) {
+	for {
+		token, err := r.decoder.Token()
+		if err != nil {
+			return nil, 0, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+
+		var row xlsxRow
+		if err := r.decoder.DecodeElement(&row, &start); err != nil {
+			return nil, 0, err
+		}
+		if row.Number == 0 {
+			row.Number = r.lastRow + 1
+		}
+		r.lastRow = row.Number
+
+		if row.Number < r.headerRow {
+			continue
+		}
+
+		record, err := r.record(row)
+		if err != nil {
+			return nil, row.Number, &errs.ValidationError{
+				Field: errs.FieldRecord,
+				Line:  row.Number,
+				Code:  errs.NewError(errs.ErrMalformedLine, err.Error()),
+			}
+		}
+		if record == nil {
+			continue
+		}
+
+		if r.columns == 0 {
+			r.columns = len(record)
+		}
+		for len(record) < r.columns {
+			record = append(record, "")
+		}
+
+		return record, row.Number, nil
+	}
+}
+
+// record returns the cell values of the row by column, or nil when all the cells are empty.
+func (r *xlsxRecordReader) record(row xlsxRow) ([]string, error) {
+	var (
+		record []string
+		empty  = true
+		column = -1
+	)
+	for _, cell := range row.Cells {
+		if cell.Ref != "" {
+			column = columnIndex(cell.Ref)
+		} else {
+			column++
+		}
+		if column < 0 {
+			return nil, fmt.Errorf("invalid cell reference %q in row %d", cell.Ref, row.Number)
+		}
+
+		value, err := r.cellValue(cell)
+		if err != nil {
+			return nil, fmt.Errorf("cell %s: %w", cell.Ref, err)
+		}
+		if value == "" {
+			continue
+		}
+
+		for len(record) <= column {
+			record = append(record, "")
+		}
+		record[column] = value
+		empty = false
+	}
+
+	if empty {
+		return nil, nil
+	}
+
+	return record, nil
+}
+
+// cellValue returns the cell value as text, the numbers are written without exponent and
+// the dates as "2006-01-02", or "2006-01-02T15:04:05" when they have a time.
+func (r *xlsxRecordReader) cellValue(cell xlsxCell) (string, error) {
+	if cell.Value == "" && cell.Inline == nil {
+		return "", nil
+	}
+
+	switch cell.Type {
+	case "s":
+		i, err := strconv.Atoi(cell.Value)
+		if err != nil || i < 0 || i >= len(r.sharedStrings) {
+			return "", fmt.Errorf("invalid shared string %q", cell.Value)
+		}
+		return r.sharedStrings[i], nil
+	case "inlineStr":
+		return cell.Inline.String(), nil
+	case "b":
+		return strconv.FormatBool(cell.Value == "1"), nil
+	case "str", "e", "d":
+		return cell.Value, nil
+	}
+
+	v, err := strconv.ParseFloat(cell.Value, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid number %q", cell.Value)
+	}
+
+	if r.dateStyles[cell.Style] {
+		return formatExcelDate(v, r.date1904), nil
+	}
+
+	return formatExcelNumber(v), nil
+}
+
+// findSheet returns the worksheet entry of the sheet with the given name, or the first sheet when the name is empty,
+// and if the workbook dates are based on 1904.
+func findSheet(entries map[string]*zip.File, name string) (*zip.File, bool, error) {
+	f, ok := entries[xlsxWorkbook]
+	if !ok {
+		return nil, false, fmt.Errorf("%s not found", xlsxWorkbook)
+	}
+
+	var workbook struct {
+		Properties struct {
+			Date1904 string `xml:"date1904,attr"`
+		} `xml:"workbookPr"`
+		Sheets []struct {
+			Name  string     `xml:"name,attr"`
+			Attrs []xml.Attr `xml:",any,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := decodeZipEntry(f, &workbook); err != nil {
+		return nil, false, err
+	}
+
+	var relID string
+	for _, sheet := range workbook.Sheets {
+		if name != "" && sheet.Name != name {
+			continue
+		}
+		for _, attr := range sheet.Attrs {
+			if attr.Name.Local == "id" {
+				relID = attr.Value
+			}
+		}
+		break
+	}
+	if relID == "" {
+		return nil, false, errs.NewError(errs.ErrSheetNotFound, name)
+	}
+
+	f, ok = entries[xlsxWorkbookRels]
+	if !ok {
+		return nil, false, fmt.Errorf("%s not found", xlsxWorkbookRels)
+	}
+
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodeZipEntry(f, &rels); err != nil {
+		return nil, false, err
+	}
+
+	for _, rel := range rels.Relationships {
+		if rel.ID != relID {
+			continue
+		}
+
+		target := path.Join(path.Dir(xlsxWorkbook), rel.Target)
+		if strings.HasPrefix(rel.Target, "/") {
+			target = strings.TrimPrefix(rel.Target, "/")
+		}
+
+		if f, ok := entries[target]; ok {
+			date1904 := workbook.Properties.Date1904 == "1" || workbook.Properties.Date1904 == "true"
+			return f, date1904, nil
+		}
+	}
+
+	return nil, false, errs.NewError(errs.ErrSheetNotFound, name)
+}
+
+func readSharedStrings(f *zip.File) ([]string, error) {
+	var sst struct {
+		Items []xlsxRichText `xml:"si"`
+	}
+	if err := decodeZipEntry(f, &sst); err != nil {
+		return nil, err
+	}
+
+	sharedStrings := make([]string, 0, len(sst.Items))
+	for i := range sst.Items {
+		sharedStrings = append(sharedStrings, sst.Items[i].String())
+	}
+
+	return sharedStrings, nil
+}
+
+// readDateStyles returns the index of the cell styles with a built-in or custom date number format.
+func readDateStyles(f *zip.File) (map[int]bool, error) {
+	var styles struct {
+		NumFmts []struct {
+			ID   int    `xml:"numFmtId,attr"`
+			Code string `xml:"formatCode,attr"`
+		} `xml:"numFmts>numFmt"`
+		CellXfs []struct {
+			NumFmtID int `xml:"numFmtId,attr"`
+		} `xml:"cellXfs>xf"`
+	}
+	if err := decodeZipEntry(f, &styles); err != nil {
+		return nil, err
+	}
+
+	customDates := make(map[int]bool)
+	for _, numFmt := range styles.NumFmts {
+		if isDateFormatCode(numFmt.Code) {
+			customDates[numFmt.ID] = true
+		}
+	}
+
+	dateStyles := make(map[int]bool)
+	for i, xf := range styles.CellXfs {
+		id := xf.NumFmtID
+		if (id >= 14 && id <= 22) || (id >= 45 && id <= 47) || customDates[id] {
+			dateStyles[i] = true
+		}
+	}
+
+	return dateStyles, nil
+}
+
+func decodeZipEntry(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("%s: %w", f.Name, err)
+	}
+
+	return nil
+}
+
+// isDateFormatCode reports if a number format code, like "dd/mm/yyyy", has date or time parts,
+// ignoring the quoted text, escaped chars and the [] sections like colors.
+func isDateFormatCode(code string) bool {
+	var (
+		quoted  bool
+		bracket bool
+		escaped bool
+	)
+	for _, r := range code {
+		switch {
+		case escaped:
+			escaped = false
+		case quoted:
+			quoted = r != '"'
+		case bracket:
+			bracket = r != ']'
+		case r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = true
+		case r == '[':
+			bracket = true
+		case strings.ContainsRune("dmyhsDMYHS", r):
+			return true
+		}
+	}
+
+	return false
+}
+
+// columnIndex returns the zero based column of a cell reference, "C12" is 2, or -1 when the reference is invalid.
+func columnIndex(ref string) int {
+	index := 0
+	i := 0
+	for ; i < len(ref) && ref[i] >= 'A' && ref[i] <= 'Z'; i++ {
+		index = index*26 + int(ref[i]-'A'+1)
+	}
+	if i == 0 {
+		return -1
+	}
+
+	return index - 1
+}
+
+// formatExcelNumber writes the number with the 15 significant digits kept by Excel, so 0.1+0.2 is "0.3".
+func formatExcelNumber(v float64) string {
+	rounded, _ := strconv.ParseFloat(strconv.FormatFloat(v, 'g', 15, 64), 64)
+	return strconv.FormatFloat(rounded, 'f', -1, 64)
+}
+
+// formatExcelDate converts a serial date to text, the serial is the number of days since 1899-12-30,
+// or since 1904-01-01 for the 1904 based workbooks, and its fraction is the time of the day.
+func formatExcelDate(serial float64, date1904 bool) string {
+	base := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	if date1904 {
+		base = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+	} else if serial < 60 {
+		// Excel counts the 1900-02-29 that does not exist, so the serials before it are one day ahead.
+		serial++
+	}
+
+	days := math.Floor(serial)
+	seconds := math.Round((serial - days) * 24 * 60 * 60)
+	t := base.AddDate(0, 0, int(days)).Add(time.Duration(seconds) * time.Second)
+
+	if seconds == 0 {
+		return t.Format("2006-01-02")
+	}
+
+	return t.Format("2006-01-02T15:04:05")
+}
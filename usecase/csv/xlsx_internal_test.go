@@ -0,0 +1,175 @@
+package csv
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	errs "github.com/vsantosalmeida/csv-parser/pkg/errors"
+)
+
+func TestXLSXRecordReader_Read(t *testing.T) {
+	file, err := os.Open("test_files/roster13.xlsx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	reader, err := newXLSXRecordReader(file, &Sheet{Name: "Employees", HeaderRow: 3})
+	assert.NoError(t, err)
+	defer reader.Close()
+
+	var (
+		gotRecords [][]string
+		gotLines   []int
+	)
+	for {
+		record, line, err := reader.Read()
+		if err != nil {
+			break
+		}
+		gotRecords = append(gotRecords, record)
+		gotLines = append(gotLines, line)
+	}
+
+	// the empty row 6 is skipped and the short row 5 is filled up to the header columns.
+	assert.Equal(t, []int{3, 4, 5, 7}, gotLines)
+	assert.Equal(t, [][]string{
+		{"Name", "E-mail", "Wage", "Emp Number", "Hired"},
+		{"John Doe", "doe@test.com", "10.5", "1", "2021-01-01"},
+		{"Mary Jane", "mary@tes.com", "15", "RT2", ""},
+		{"Max Topperson", "max.test.com", "11", "3", "2022-01-01T12:00:00"},
+	}, gotRecords)
+}
+
+func TestXLSXRecordReader_Read_BadCell(t *testing.T) {
+	// the salary of the row 5 is not a number.
+	workbook := replaceZipEntry(t, "test_files/roster13.xlsx", "xl/worksheets/sheet2.xml",
+		`<c r="C5"><v>15.000000000000002</v></c>`, `<c r="C5"><v>abc</v></c>`)
+
+	reader, err := newXLSXRecordReader(bytes.NewReader(workbook), &Sheet{Name: "Employees", HeaderRow: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	var (
+		gotLines     []int
+		gotMalformed []*errs.ValidationError
+	)
+	for {
+		_, line, err := reader.Read()
+		var malformed *errs.ValidationError
+		if errors.As(err, &malformed) {
+			gotMalformed = append(gotMalformed, malformed)
+			continue
+		}
+		if err != nil {
+			break
+		}
+		gotLines = append(gotLines, line)
+	}
+
+	assert.Equal(t, []int{3, 4, 7}, gotLines)
+	if assert.Len(t, gotMalformed, 1) {
+		assert.Equal(t, 5, gotMalformed[0].Line)
+		assert.True(t, errors.Is(gotMalformed[0].Code, errs.ErrMalformedLine))
+		assert.Contains(t, gotMalformed[0].Code.Error(), `invalid number "abc"`)
+	}
+}
+
+// replaceZipEntry returns the zip file with the old text replaced by the new one in the entry.
+func replaceZipEntry(t *testing.T, file, entry, old, new string) []byte {
+	archive, err := zip.OpenReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, f := range archive.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.Name == entry {
+			b = []byte(strings.Replace(string(b), old, new, 1))
+		}
+
+		fw, err := w.Create(f.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestFormatExcelDate(t *testing.T) {
+	tt := []struct {
+		name          string
+		givenSerial   float64
+		givenDate1904 bool
+		want          string
+	}{
+		{
+			name:        "First day of 1900",
+			givenSerial: 1,
+			want:        "1900-01-01",
+		},
+		{
+			name:        "After the 1900 leap day",
+			givenSerial: 61,
+			want:        "1900-03-01",
+		},
+		{
+			name:        "Date and time",
+			givenSerial: 44197.75,
+			want:        "2021-01-01T18:00:00",
+		},
+		{
+			name:          "1904 based workbook",
+			givenSerial:   42735,
+			givenDate1904: true,
+			want:          "2021-01-01",
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, formatExcelDate(tc.givenSerial, tc.givenDate1904))
+		})
+	}
+}
+
+func TestIsDateFormatCode(t *testing.T) {
+	tt := map[string]bool{
+		"dd/mm/yyyy":            true,
+		"[$-409]mmmm d, yyyy":   true,
+		"h:mm AM/PM":            true,
+		"0.00":                  false,
+		"General":               false,
+		`#,##0.00 "days"`:       false,
+		"[Red]#,##0;[Blue]-0.0": false,
+		`0.0\d`:                 false,
+	}
+	for code, want := range tt {
+		assert.Equal(t, want, isDateFormatCode(code), code)
+	}
+}
@@ -0,0 +1,180 @@
+package csv
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// xmlRecordReader is a recordReader for a XML file with a repeating element for each employee.
+//
+// The header is made by the FilePattern columns, which are paths from the repeating element, like "name/first"
+// for a child element text or "@id" and "contact/@type" for an attribute, and each repeating element is a record.
+// The elements are read one by one, so the whole file is not loaded in memory.
+type xmlRecordReader struct {
+	decoder *xml.Decoder
+	counter *lineCounter
+	// element is the name of the repeating element, each child of the root element when empty.
+	element string
+	columns []string
+	header  bool
+	// depth is the number of open elements.
+	depth int
+	// encoding is the name of the file encoding, detected when the FilePattern has none.
+	encoding string
+}
+
+// newXMLRecordReader transcodes r to UTF-8 and reads the FilePattern Element of the XML file.
+func newXMLRecordReader(r io.Reader, pattern *FilePattern) (*xmlRecordReader, error) {
+	decoded, encodingName, err := newDecoder(r, pattern.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	counter := &lineCounter{reader: decoded}
+	decoder := xml.NewDecoder(counter)
+	// the file is already transcoded, so the encoding of the XML declaration is ignored.
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	return &xmlRecordReader{
+		decoder:  decoder,
+		counter:  counter,
+		element:  pattern.Element,
+		columns:  pattern.columns(),
+		encoding: encodingName,
+	}, nil
+}
+
+func (r *xmlRecordReader) Read() ([]string, int, error) {
+	if !r.header {
+		r.header = true
+		return r.columns, 1, nil
+	}
+
+	values, line, err := r.next()
+	if err != nil {
+		return nil, line, err
+	}
+
+	record := make([]string, 0, len(r.columns))
+	for _, column := range r.columns {
+		record = append(record, values[strings.Trim(column, "/")])
+	}
+
+	return record, line, nil
+}
+
+// next finds the next repeating element and returns its values by path, with the line where it starts.
+func (r *xmlRecordReader) next() (map[string]string, int, error) {
+	for {
+		offset := r.decoder.InputOffset()
+		token, err := r.decoder.Token()
+		if err != nil {
+			return nil, 0, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			r.depth++
+			if (r.element == "" && r.depth == 2) || t.Name.Local == r.element {
+				line := r.counter.line(offset)
+				values, err := r.readElement(t)
+				r.depth--
+				return values, line, err
+			}
+		case xml.EndElement:
+			r.depth--
+		}
+	}
+}
+
+// readElement reads the element until its end, returning the text of the child elements and the attributes
+// by path. When an element is repeated only the first one is kept.
+func (r *xmlRecordReader) readElement(start xml.StartElement) (map[string]string, error) {
+	values := make(map[string]string)
+	addAttrs(values, "", start.Attr)
+
+	type openElement struct {
+		path     string
+		text     strings.Builder
+		children bool
+	}
+	var stack []*openElement
+	for {
+		token, err := r.decoder.Token()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			path := t.Name.Local
+			if len(stack) != 0 {
+				stack[len(stack)-1].children = true
+				path = stack[len(stack)-1].path + "/" + path
+			}
+			stack = append(stack, &openElement{path: path})
+			addAttrs(values, path+"/", t.Attr)
+		case xml.CharData:
+			if len(stack) != 0 {
+				stack[len(stack)-1].text.Write(t)
+			}
+		case xml.EndElement:
+			if len(stack) == 0 {
+				return values, nil
+			}
+			element := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			text := strings.TrimSpace(element.text.String())
+			// the elements with only child elements have no value.
+			if element.children && text == "" {
+				continue
+			}
+			if _, ok := values[element.path]; !ok {
+				values[element.path] = text
+			}
+		}
+	}
+}
+
+func addAttrs(values map[string]string, prefix string, attrs []xml.Attr) {
+	for _, attr := range attrs {
+		if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+			continue
+		}
+		if _, ok := values[prefix+"@"+attr.Name.Local]; !ok {
+			values[prefix+"@"+attr.Name.Local] = attr.Value
+		}
+	}
+}
+
+// readXMLColumns returns the paths of the first repeating element of a XML file, with the detected encoding.
+func readXMLColumns(r io.Reader, element string) ([]string, string, error) {
+	reader, err := newXMLRecordReader(r, &FilePattern{Element: element})
+	if err != nil {
+		return nil, "", err
+	}
+
+	values, _, err := reader.next()
+	if err != nil {
+		if err == io.EOF {
+			err = fmt.Errorf("no element found")
+		}
+		return nil, "", err
+	}
+
+	columns := make([]string, 0, len(values))
+	for path := range values {
+		columns = append(columns, path)
+	}
+	sort.Strings(columns)
+
+	return columns, reader.encoding, nil
+}
@@ -0,0 +1,66 @@
+package csv
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestXMLRecordReader_Read(t *testing.T) {
+	givenFile := `<?xml version="1.0" encoding="ISO-8859-1"?>
+<employees>
+  <employee id="1"><name>John</name><contact type="work">doe@test.com</contact></employee>
+  <employee id="2">
+    <name>Mary</name>
+  </employee>
+</employees>`
+
+	reader, err := newXMLRecordReader(strings.NewReader(givenFile), &FilePattern{
+		FirstNameColumn: "name",
+		EmailColumn:     "contact",
+		IDColumn:        "@id",
+		PhoneColumn:     "contact/@type",
+	})
+	assert.NoError(t, err)
+
+	var (
+		gotRecords [][]string
+		gotLines   []int
+	)
+	for {
+		record, line, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		gotRecords = append(gotRecords, record)
+		gotLines = append(gotLines, line)
+	}
+
+	assert.Equal(t, []int{1, 3, 4}, gotLines)
+	assert.Equal(t, [][]string{
+		{"name", "contact", "@id", "contact/@type"},
+		{"John", "doe@test.com", "1", "work"},
+		{"Mary", "", "2", ""},
+	}, gotRecords)
+}
+
+func TestXMLRecordReader_Read_UnexpectedEOF(t *testing.T) {
+	reader, err := newXMLRecordReader(strings.NewReader(`<employees><employee id="1"><name>John`), &FilePattern{IDColumn: "@id"})
+	assert.NoError(t, err)
+
+	_, _, err = reader.Read()
+	assert.NoError(t, err)
+
+	_, _, err = reader.Read()
+	assert.Error(t, err)
+	assert.NotEqual(t, io.EOF, err)
+}
+
+func TestReadXMLColumns(t *testing.T) {
+	columns, _, err := readXMLColumns(strings.NewReader(`<employees><employee id="1"><name><first>John</first></name></employee></employees>`), "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"@id", "name/first"}, columns)
+}
@@ -0,0 +1,42 @@
+package job
+
+import (
+	"time"
+
+	"github.com/vsantosalmeida/csv-parser/usecase/csv"
+)
+
+// State of a Job.
+type State string
+
+// States of a Job, a queued job waits for a free worker and the last three are final.
+const (
+	StateQueued    State = "queued"
+	StateRunning   State = "running"
+	StateSucceeded State = "succeeded"
+	StateFailed    State = "failed"
+	StateCancelled State = "cancelled"
+)
+
+// Job is a file parsed in background by the Service, the uploaded file and its result files are kept
+// in the job folder.
+type Job struct {
+	ID      string           `json:"id"`
+	File    string           `json:"file"`
+	Pattern *csv.FilePattern `json:"pattern"`
+	State   State            `json:"state"`
+	// Progress holds the counters of the lines read, updated while the job is running.
+	Progress csv.Progress `json:"progress"`
+	// Summary is set when the job finishes, succeeded jobs have the result files in it.
+	Summary *csv.Summary `json:"summary,omitempty"`
+	// Error is set when the job failed, the error of each file is in the Summary.
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Finished returns true when the Job is in a final State.
+func (j *Job) Finished() bool {
+	return j.State == StateSucceeded || j.State == StateFailed || j.State == StateCancelled
+}
@@ -0,0 +1,415 @@
+package job
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	errs "github.com/vsantosalmeida/csv-parser/pkg/errors"
+	"github.com/vsantosalmeida/csv-parser/usecase/csv"
+)
+
+// progressSaveInterval is the min time between two saves of the Progress of a running job.
+const progressSaveInterval = time.Second
+
+// Config configures the Service.
+type Config struct {
+	// Dir is the folder with a subfolder for the file and result files of each job.
+	Dir string
+	// Workers is the max number of jobs running at the same time, 1 when 0.
+	Workers int
+	// Policy is the csv.Policy of the parser of each job.
+	Policy csv.Policy
+}
+
+// running is a job not finished yet, with the func cancelling its run.
+type running struct {
+	job       *Job
+	cancel    context.CancelFunc
+	lastSaved time.Time
+}
+
+// Service parses each submitted file in background as a Job, keeping at most Config.Workers jobs running.
+//
+// The metadata of the jobs is persisted to the Store on each change of State, and at most each second while
+// the Progress changes.
+type Service struct {
+	config Config
+	store  Store
+	// slots limits the jobs running at the same time.
+	slots  chan struct{}
+	mu     sync.Mutex
+	active map[string]*running
+	wg     sync.WaitGroup
+	now    func() time.Time
+}
+
+// NewService validates the Config and marks the jobs left queued or running by a previous process as failed.
+func NewService(config Config, store Store) (*Service, error) {
+	if config.Dir == "" {
+		return nil, fmt.Errorf("the jobs folder is required")
+	}
+	if config.Workers <= 0 {
+		config.Workers = 1
+	}
+	if err := config.Policy.Validate(); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(config.Dir, 0755); err != nil {
+		return nil, err
+	}
+
+	s := &Service{
+		config: config,
+		store:  store,
+		slots:  make(chan struct{}, config.Workers),
+		active: make(map[string]*running),
+		now:    func() time.Time { return time.Now().UTC() },
+	}
+
+	jobs, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, job := range jobs {
+		if job.Finished() {
+			continue
+		}
+
+		log.WithFields(log.Fields{
+			"event": "job_interrupted",
+			"job":   job.ID,
+			"state": job.State,
+		}).Warn("the job was not finished by the previous process")
+		finishedAt := s.now()
+		job.State, job.Error, job.FinishedAt = StateFailed, errs.ErrJobInterrupted.Error(), &finishedAt
+		if err := store.Save(job); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// Submit saves the file in a new job folder and queues its Job, the name sets the input format like
+// "roster.xlsx" and only its base name is used.
+//
+// An errs.ErrInvalidJob error is returned when the name or the FilePattern are invalid, or the file
+// could not be read.
+func (s *Service) Submit(name string, r io.Reader, pattern *csv.FilePattern) (*Job, error) {
+	name, err := baseName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(s.config.Dir, id, name)
+	if _, err := csv.NewParser(map[string]*csv.FilePattern{path: pattern}, csv.WithPolicy(s.config.Policy)); err != nil {
+		return nil, errs.NewError(errs.ErrInvalidJob, err.Error())
+	}
+
+	if err := saveFile(path, r); err != nil {
+		os.RemoveAll(filepath.Dir(path))
+		return nil, err
+	}
+
+	job := &Job{
+		ID:        id,
+		File:      name,
+		Pattern:   pattern,
+		State:     StateQueued,
+		CreatedAt: s.now(),
+	}
+	if err := s.store.Save(job); err != nil {
+		os.RemoveAll(filepath.Dir(path))
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.mu.Lock()
+	s.active[id] = &running{job: job, cancel: cancel}
+	snapshot := *job
+	s.mu.Unlock()
+
+	log.WithFields(log.Fields{
+		"event": "job_queued",
+		"job":   id,
+		"file":  name,
+	}).Info()
+
+	s.wg.Add(1)
+	go s.run(ctx, job, path)
+
+	return &snapshot, nil
+}
+
+// Get returns a copy of the Job, or an errs.ErrJobNotFound error.
+func (s *Service) Get(id string) (*Job, error) {
+	s.mu.Lock()
+	if r, ok := s.active[id]; ok {
+		snapshot := *r.job
+		s.mu.Unlock()
+		return &snapshot, nil
+	}
+	s.mu.Unlock()
+
+	return s.store.Get(id)
+}
+
+// List returns all the jobs, the most recent first.
+func (s *Service) List() ([]*Job, error) {
+	jobs, err := s.store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	// the active jobs have a Progress newer than the stored one.
+	s.mu.Lock()
+	for i, job := range jobs {
+		if r, ok := s.active[job.ID]; ok {
+			snapshot := *r.job
+			jobs[i] = &snapshot
+		}
+	}
+	s.mu.Unlock()
+	sortJobs(jobs)
+
+	return jobs, nil
+}
+
+// Cancel stops a queued or running Job, returning an errs.ErrJobFinished error when it has already finished.
+//
+// The Job is only in the cancelled State after its run stops, a running job is stopped before its next line.
+func (s *Service) Cancel(id string) (*Job, error) {
+	s.mu.Lock()
+	r, ok := s.active[id]
+	if ok {
+		r.cancel()
+		snapshot := *r.job
+		s.mu.Unlock()
+
+		log.WithFields(log.Fields{
+			"event": "job_cancel_requested",
+			"job":   id,
+		}).Info()
+
+		return &snapshot, nil
+	}
+	s.mu.Unlock()
+
+	if _, err := s.store.Get(id); err != nil {
+		return nil, err
+	}
+
+	return nil, errs.NewError(errs.ErrJobFinished, id)
+}
+
+// Wait blocks until the Job has finished or the ctx is done, returning its last copy.
+func (s *Service) Wait(ctx context.Context, id string) (*Job, error) {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		job, err := s.Get(id)
+		if err != nil || job.Finished() {
+			return job, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return job, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Close cancels the jobs not finished yet and waits for their runs to stop.
+func (s *Service) Close() {
+	s.mu.Lock()
+	for _, r := range s.active {
+		r.cancel()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+// run waits for a free slot and parses the file of the Job, writing the result files in the job folder.
+func (s *Service) run(ctx context.Context, job *Job, path string) {
+	defer s.wg.Done()
+
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-ctx.Done():
+		s.finish(job, StateCancelled, nil, "")
+		return
+	}
+	if ctx.Err() != nil {
+		s.finish(job, StateCancelled, nil, "")
+		return
+	}
+
+	s.update(job, func(job *Job) {
+		startedAt := s.now()
+		job.State, job.StartedAt = StateRunning, &startedAt
+	})
+	log.WithFields(log.Fields{
+		"event": "job_started",
+		"job":   job.ID,
+	}).Info()
+
+	parser, err := csv.NewParser(map[string]*csv.FilePattern{path: job.Pattern},
+		csv.WithPolicy(s.config.Policy),
+		csv.WithOutputDir(filepath.Dir(path)),
+		csv.WithContext(ctx),
+		csv.WithProgress(func(progress csv.Progress) { s.setProgress(job, progress) }),
+	)
+	if err != nil {
+		s.finish(job, StateFailed, nil, err.Error())
+		return
+	}
+
+	errors := parser.ParseFiles([]string{path})
+	switch {
+	case ctx.Err() != nil:
+		s.finish(job, StateCancelled, parser.Summary(), "")
+	case len(errors) != 0:
+		s.finish(job, StateFailed, parser.Summary(), errorMessage(errors))
+	default:
+		s.finish(job, StateSucceeded, parser.Summary(), "")
+	}
+}
+
+// setProgress updates the Progress of a running Job, saving it when the last save is older than progressSaveInterval.
+func (s *Service) setProgress(job *Job, progress csv.Progress) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job.Progress = progress
+	r := s.active[job.ID]
+	if now := s.now(); now.Sub(r.lastSaved) >= progressSaveInterval {
+		r.lastSaved = now
+		s.save(job)
+	}
+}
+
+// update applies fn to the Job and saves it.
+func (s *Service) update(job *Job, fn func(job *Job)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fn(job)
+	s.save(job)
+}
+
+// finish sets the final State of the Job, saves it and removes it from the active jobs.
+func (s *Service) finish(job *Job, state State, summary *csv.Summary, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	finishedAt := s.now()
+	job.State, job.Summary, job.Error, job.FinishedAt = state, summary, message, &finishedAt
+	s.save(job)
+	s.active[job.ID].cancel()
+	delete(s.active, job.ID)
+
+	log.WithFields(log.Fields{
+		"event":    "job_finished",
+		"job":      job.ID,
+		"state":    state,
+		"rows":     job.Progress.Rows,
+		"accepted": job.Progress.Accepted,
+		"rejected": job.Progress.Rejected,
+	}).Info()
+}
+
+// save must be called holding the mu, a failure is only logged since the Job is still in memory.
+func (s *Service) save(job *Job) {
+	if err := s.store.Save(job); err != nil {
+		log.WithFields(log.Fields{
+			"event":  "save_job_failed",
+			"job":    job.ID,
+			"reason": err,
+		}).Error("could not save the job metadata")
+	}
+}
+
+// errorMessage joins the messages of the errors returned by ParseFiles, sorted so the message is stable.
+func errorMessage(errors map[string]error) string {
+	messages := make([]string, 0, len(errors))
+	for _, err := range errors {
+		messages = append(messages, err.Error())
+	}
+	sort.Strings(messages)
+
+	return strings.Join(messages, "; ")
+}
+
+// baseName returns the base name of an uploaded file, so it can not be written outside the job folder.
+func baseName(name string) (string, error) {
+	base := filepath.Base(filepath.Clean("/" + strings.ReplaceAll(name, `\`, "/")))
+	if name == "" || base == "/" || base == metadataFile || strings.HasPrefix(base, ".") {
+		return "", errs.NewError(errs.ErrInvalidJob, fmt.Sprintf("invalid file name %q", name))
+	}
+
+	return base, nil
+}
+
+func saveFile(path string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		return &readError{err: err}
+	}
+
+	return file.Close()
+}
+
+// readError is returned when the uploaded file could not be read, it is an errs.ErrInvalidJob that also
+// unwraps to the read error, so the caller can find the cause, like the size limit of the upload.
+type readError struct {
+	err error
+}
+
+func (e *readError) Error() string {
+	return errs.NewError(errs.ErrInvalidJob, fmt.Sprintf("could not read the file: %s", e.err)).Error()
+}
+
+func (e *readError) Is(target error) bool {
+	return target == errs.ErrInvalidJob
+}
+
+func (e *readError) Unwrap() error {
+	return e.err
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
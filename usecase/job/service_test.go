@@ -0,0 +1,215 @@
+package job_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/vsantosalmeida/csv-parser/pkg/errors"
+	"github.com/vsantosalmeida/csv-parser/usecase/csv"
+	"github.com/vsantosalmeida/csv-parser/usecase/job"
+)
+
+const givenRoster = "Name,Email,Wage,Number\nJohn Doe,doe@test.com,$10.00,1\nMary Jane,marytest.com,$15,2\n"
+
+var givenPattern = &csv.FilePattern{
+	FirstNameColumn: "Name",
+	SalaryColumn:    "Wage",
+	EmailColumn:     "Email",
+	IDColumn:        "Number",
+}
+
+func init() {
+	log.SetOutput(ioutil.Discard)
+}
+
+func newService(t *testing.T, workers int) (*job.Service, *job.FileStore) {
+	dir, err := ioutil.TempDir("", "jobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	store, err := job.NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svc, err := job.NewService(job.Config{Dir: dir, Workers: workers}, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(svc.Close)
+
+	return svc, store
+}
+
+func wait(t *testing.T, svc *job.Service, id string) *job.Job {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	got, err := svc.Wait(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return got
+}
+
+// largeRoster returns a roster with n valid lines.
+func largeRoster(n int) string {
+	var b strings.Builder
+	b.WriteString("Name,Email,Wage,Number\n")
+	for i := 0; i < n; i++ {
+		b.WriteString("John Doe,,$10.00,")
+		b.WriteString(strings.Repeat("1", 1+i%5))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func TestService_Submit(t *testing.T) {
+	svc, store := newService(t, 1)
+
+	submitted, err := svc.Submit("roster.csv", strings.NewReader(givenRoster), givenPattern)
+	assert.NoError(t, err)
+	assert.Equal(t, "roster.csv", submitted.File)
+	assert.Contains(t, []job.State{job.StateQueued, job.StateRunning, job.StateSucceeded}, submitted.State)
+
+	got := wait(t, svc, submitted.ID)
+	assert.Equal(t, job.StateSucceeded, got.State)
+	assert.Equal(t, csv.Progress{Rows: 2, Accepted: 1, Rejected: 1}, got.Progress)
+	assert.NotNil(t, got.StartedAt)
+	assert.NotNil(t, got.FinishedAt)
+	if assert.NotNil(t, got.Summary) {
+		assert.Equal(t, 1, got.Summary.Employees)
+		assert.FileExists(t, got.Summary.EmployeesFile)
+		assert.FileExists(t, got.Summary.BadDataFile)
+	}
+
+	stored, err := store.Get(submitted.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, got, stored)
+
+	jobs, err := svc.List()
+	assert.NoError(t, err)
+	if assert.Len(t, jobs, 1) {
+		assert.Equal(t, submitted.ID, jobs[0].ID)
+	}
+}
+
+func TestService_Submit_Failed(t *testing.T) {
+	svc, _ := newService(t, 1)
+
+	// the content is not a workbook, so the file can not be read.
+	submitted, err := svc.Submit("roster.xlsx", strings.NewReader(givenRoster), givenPattern)
+	assert.NoError(t, err)
+
+	got := wait(t, svc, submitted.ID)
+	assert.Equal(t, job.StateFailed, got.State)
+	assert.NotEmpty(t, got.Error)
+	if assert.NotNil(t, got.Summary) {
+		assert.Len(t, got.Summary.Errors, 1)
+	}
+}
+
+func TestService_Submit_Invalid(t *testing.T) {
+	svc, _ := newService(t, 1)
+
+	_, err := svc.Submit("roster.csv", strings.NewReader(givenRoster), &csv.FilePattern{EmailColumn: "Email"})
+	assert.ErrorIs(t, err, errors.ErrInvalidJob)
+
+	_, err = svc.Submit("../", strings.NewReader(givenRoster), givenPattern)
+	assert.ErrorIs(t, err, errors.ErrInvalidJob)
+
+	jobs, err := svc.List()
+	assert.NoError(t, err)
+	assert.Empty(t, jobs)
+}
+
+func TestService_Cancel(t *testing.T) {
+	svc, _ := newService(t, 1)
+
+	first, err := svc.Submit("large.csv", strings.NewReader(largeRoster(200000)), givenPattern)
+	assert.NoError(t, err)
+	second, err := svc.Submit("roster.csv", strings.NewReader(givenRoster), givenPattern)
+	assert.NoError(t, err)
+
+	// the second job is queued until the first one finishes.
+	_, err = svc.Cancel(second.ID)
+	assert.NoError(t, err)
+	got := wait(t, svc, second.ID)
+	assert.Equal(t, job.StateCancelled, got.State)
+	assert.Nil(t, got.StartedAt)
+
+	_, err = svc.Cancel(first.ID)
+	assert.NoError(t, err)
+	got = wait(t, svc, first.ID)
+	assert.Equal(t, job.StateCancelled, got.State)
+	if assert.NotNil(t, got.Summary) {
+		assert.Empty(t, got.Summary.EmployeesFile)
+	}
+
+	_, err = svc.Cancel(first.ID)
+	assert.ErrorIs(t, err, errors.ErrJobFinished)
+	_, err = svc.Cancel("unknown")
+	assert.ErrorIs(t, err, errors.ErrJobNotFound)
+}
+
+func TestNewService_Interrupted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := job.NewFileStore(dir)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Save(&job.Job{ID: "a1", File: "roster.csv", State: job.StateRunning}))
+	assert.NoError(t, store.Save(&job.Job{ID: "b2", File: "roster.csv", State: job.StateSucceeded}))
+
+	_, err = job.NewService(job.Config{Dir: dir}, store)
+	assert.NoError(t, err)
+
+	got, err := store.Get("a1")
+	assert.NoError(t, err)
+	assert.Equal(t, job.StateFailed, got.State)
+	assert.Equal(t, errors.ErrJobInterrupted.Error(), got.Error)
+
+	got, err = store.Get("b2")
+	assert.NoError(t, err)
+	assert.Equal(t, job.StateSucceeded, got.State)
+}
+
+func TestFileStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := job.NewFileStore(dir)
+	assert.NoError(t, err)
+
+	givenTime := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	assert.NoError(t, store.Save(&job.Job{ID: "old", State: job.StateSucceeded, CreatedAt: givenTime}))
+	assert.NoError(t, store.Save(&job.Job{ID: "new", State: job.StateQueued, CreatedAt: givenTime.Add(time.Hour)}))
+	assert.NoError(t, os.Mkdir(dir+"/other", 0755))
+
+	jobs, err := store.List()
+	assert.NoError(t, err)
+	if assert.Len(t, jobs, 2) {
+		assert.Equal(t, "new", jobs[0].ID)
+		assert.Equal(t, "old", jobs[1].ID)
+	}
+
+	_, err = store.Get("missing")
+	assert.ErrorIs(t, err, errors.ErrJobNotFound)
+	_, err = store.Get("../old")
+	assert.ErrorIs(t, err, errors.ErrJobNotFound)
+}
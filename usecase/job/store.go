@@ -0,0 +1,138 @@
+package job
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	errs "github.com/vsantosalmeida/csv-parser/pkg/errors"
+)
+
+// metadataFile is the name of the file with the Job metadata in the job folder.
+const metadataFile = "job.json"
+
+// Store persists the metadata of the jobs.
+type Store interface {
+	// Save creates or replaces the Job.
+	Save(job *Job) error
+	// Get returns the Job or errs.ErrJobNotFound.
+	Get(id string) (*Job, error)
+	// List returns all the jobs, the most recent first.
+	List() ([]*Job, error)
+}
+
+// FileStore is a Store keeping the metadata of each Job as JSON in its folder, "<dir>/<id>/job.json".
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore returns a FileStore on the given folder, creating it when missing.
+func NewFileStore(dir string) (*FileStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("the jobs folder is required")
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	return &FileStore{dir: dir}, nil
+}
+
+// Dir returns the folder of the jobs.
+func (f *FileStore) Dir() string {
+	return f.dir
+}
+
+// Save writes the metadata to a temp file renamed over the previous one, so a reader never sees a partial file.
+func (f *FileStore) Save(job *Job) error {
+	if !validID(job.ID) {
+		return errs.NewError(errs.ErrJobNotFound, job.ID)
+	}
+
+	dir := filepath.Join(f.dir, job.ID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := filepath.Join(dir, metadataFile+".tmp")
+	if err := ioutil.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, filepath.Join(dir, metadataFile))
+}
+
+func (f *FileStore) Get(id string) (*Job, error) {
+	if !validID(id) {
+		return nil, errs.NewError(errs.ErrJobNotFound, id)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(f.dir, id, metadataFile))
+	if os.IsNotExist(err) {
+		return nil, errs.NewError(errs.ErrJobNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var job Job
+	if err := json.Unmarshal(b, &job); err != nil {
+		return nil, fmt.Errorf("could not decode the job %s: %w", id, err)
+	}
+
+	return &job, nil
+}
+
+// List ignores the subfolders without a metadata file.
+func (f *FileStore) List() ([]*Job, error) {
+	entries, err := ioutil.ReadDir(f.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*Job, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		job, err := f.Get(entry.Name())
+		if stderrors.Is(err, errs.ErrJobNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	sortJobs(jobs)
+
+	return jobs, nil
+}
+
+// sortJobs sorts the jobs by creation time, the most recent first.
+func sortJobs(jobs []*Job) {
+	sort.Slice(jobs, func(i, j int) bool {
+		if !jobs[i].CreatedAt.Equal(jobs[j].CreatedAt) {
+			return jobs[i].CreatedAt.After(jobs[j].CreatedAt)
+		}
+		return jobs[i].ID < jobs[j].ID
+	})
+}
+
+// validID returns false for the IDs that are not a single path element.
+func validID(id string) bool {
+	return id != "" && id != "." && id != ".." && !strings.ContainsAny(id, `/\`)
+}
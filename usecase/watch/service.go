@@ -0,0 +1,306 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vsantosalmeida/csv-parser/usecase/csv"
+)
+
+// Status of a file processed by the Service.
+const (
+	StatusProcessed = "processed"
+	StatusFailed    = "failed"
+)
+
+// resultFile is the name of the file with the Result, written with the result files of each processed file.
+const resultFile = "result.json"
+
+// Config configures the folders and how the Service finds the files ready to be processed.
+type Config struct {
+	// Inbox is the folder watched for new files, its subfolders are ignored.
+	Inbox string
+	// Processed and Failed are the folders where the files are moved after being processed.
+	Processed string
+	Failed    string
+	// Results is the folder with a subfolder for the result files of each processed file.
+	Results string
+	// Interval is the time between each scan of the Inbox.
+	Interval time.Duration
+	// StableFor is the time a file must keep its size and modification time to be processed.
+	StableFor time.Duration
+	// Marker is the suffix of the marker files, when not empty a file is only processed after the file with
+	// its name and the Marker is created, like "roster.csv.done", and the StableFor is ignored.
+	Marker string
+}
+
+// ParserFactory creates the csv.Parser of a file, writing the result files in the given folder.
+type ParserFactory func(outputDir string) (csv.Parser, error)
+
+// Result of a file processed by the Service.
+type Result struct {
+	File   string `json:"file"`
+	Status string `json:"status"`
+	// MovedTo is the path of the file in the Processed or Failed folder.
+	MovedTo string `json:"moved_to"`
+	// ResultsDir is the folder with the result files.
+	ResultsDir string       `json:"results_dir"`
+	Summary    *csv.Summary `json:"summary,omitempty"`
+	// Error is set when the file could not be parsed or moved.
+	Error string `json:"error,omitempty"`
+}
+
+// fileState is the size and modification time of a file in the Inbox, since the last time one of them changed.
+type fileState struct {
+	size    int64
+	modTime time.Time
+	since   time.Time
+}
+
+// Service watches the Inbox folder, processing each new file once it is fully written with a csv.Parser.
+type Service struct {
+	config    Config
+	newParser ParserFactory
+	files     map[string]*fileState
+	// stuck are the files that could not be moved out of the Inbox, skipped until their size or
+	// modification time changes.
+	stuck map[string]*fileState
+	now   func() time.Time
+}
+
+// NewService validates the Config and creates the Processed, Failed and Results folders.
+func NewService(config Config, newParser ParserFactory) (*Service, error) {
+	if config.Inbox == "" {
+		return nil, fmt.Errorf("the inbox folder is required")
+	}
+	if info, err := os.Stat(config.Inbox); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("the inbox %q must be a folder", config.Inbox)
+	}
+	if config.Interval <= 0 || config.StableFor < 0 {
+		return nil, fmt.Errorf("the interval must be greater than 0 and the stable time must not be negative")
+	}
+
+	for _, dir := range []string{config.Processed, config.Failed, config.Results} {
+		if dir == "" {
+			return nil, fmt.Errorf("the processed, failed and results folders are required")
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Service{
+		config:    config,
+		newParser: newParser,
+		files:     make(map[string]*fileState),
+		stuck:     make(map[string]*fileState),
+		now:       time.Now,
+	}, nil
+}
+
+// Run scans the Inbox each Interval until the ctx is done.
+func (s *Service) Run(ctx context.Context) error {
+	log.WithFields(log.Fields{
+		"event": "watch_started",
+		"inbox": s.config.Inbox,
+	}).Info()
+
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.Poll(); err != nil {
+			log.WithFields(log.Fields{
+				"event":  "watch_scan_failed",
+				"inbox":  s.config.Inbox,
+				"reason": err,
+			}).Error("could not scan the inbox")
+		}
+
+		select {
+		case <-ctx.Done():
+			log.WithFields(log.Fields{
+				"event": "watch_stopped",
+				"inbox": s.config.Inbox,
+			}).Info()
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll scans the Inbox once and processes the files ready, returning their results sorted by file name.
+func (s *Service) Poll() ([]*Result, error) {
+	ready, err := s.readyFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*Result, 0, len(ready))
+	for _, file := range ready {
+		results = append(results, s.process(file))
+	}
+
+	return results, nil
+}
+
+// readyFiles returns the files of the Inbox with a marker or with the same size and modification time
+// for the StableFor time. The hidden files, like ".roster.csv", and the ".tmp" and ".part" files are ignored,
+// as the files that could not be moved until they change.
+func (s *Service) readyFiles() ([]string, error) {
+	entries, err := ioutil.ReadDir(s.config.Inbox)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		now     = s.now()
+		ready   []string
+		names   = make(map[string]bool, len(entries))
+		current = make(map[string]*fileState, len(entries))
+		stuck   = make(map[string]*fileState, len(s.stuck))
+	)
+	for _, entry := range entries {
+		names[entry.Name()] = true
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || ignored(name) || (s.config.Marker != "" && strings.HasSuffix(name, s.config.Marker)) {
+			continue
+		}
+
+		if state, ok := s.stuck[name]; ok && state.size == entry.Size() && state.modTime.Equal(entry.ModTime()) {
+			stuck[name] = state
+			continue
+		}
+
+		if s.config.Marker != "" {
+			if names[name+s.config.Marker] {
+				ready = append(ready, name)
+			}
+			continue
+		}
+
+		state, ok := s.files[name]
+		if !ok || state.size != entry.Size() || !state.modTime.Equal(entry.ModTime()) {
+			state = &fileState{size: entry.Size(), modTime: entry.ModTime(), since: now}
+		}
+		current[name] = state
+
+		if now.Sub(state.since) >= s.config.StableFor {
+			ready = append(ready, name)
+		}
+	}
+	s.files, s.stuck = current, stuck
+	sort.Strings(ready)
+
+	return ready, nil
+}
+
+func ignored(name string) bool {
+	return strings.HasPrefix(name, ".") || strings.HasSuffix(name, ".tmp") || strings.HasSuffix(name, ".part")
+}
+
+// process parses the file with its own csv.Parser and moves it to the Processed folder, or to the Failed folder
+// when ParseFiles returns any error, writing the Result with the result files.
+func (s *Service) process(name string) *Result {
+	var (
+		file      = filepath.Join(s.config.Inbox, name)
+		timestamp = s.now().Format("20060102150405")
+		result    = &Result{
+			File:       file,
+			Status:     StatusProcessed,
+			ResultsDir: filepath.Join(s.config.Results, name+"-"+timestamp),
+		}
+	)
+	log.WithFields(log.Fields{
+		"event": "watch_processing_file",
+		"file":  file,
+	}).Info()
+
+	parser, err := s.newParser(result.ResultsDir)
+	if err != nil {
+		result.Status, result.Error = StatusFailed, err.Error()
+	} else if errs := parser.ParseFiles([]string{file}); len(errs) != 0 {
+		result.Status = StatusFailed
+	}
+	if parser != nil {
+		result.Summary = parser.Summary()
+	}
+
+	dir := s.config.Processed
+	if result.Status == StatusFailed {
+		dir = s.config.Failed
+	}
+	result.MovedTo, err = move(file, dir, timestamp)
+	if err != nil {
+		result.Status, result.Error = StatusFailed, err.Error()
+		if info, statErr := os.Stat(file); statErr == nil {
+			s.stuck[name] = &fileState{size: info.Size(), modTime: info.ModTime()}
+		}
+		log.WithFields(log.Fields{
+			"event":  "watch_move_failed",
+			"file":   file,
+			"dir":    dir,
+			"reason": err,
+		}).Error("could not move the file, it is skipped until it changes")
+	}
+	delete(s.files, name)
+	if s.config.Marker != "" {
+		os.Remove(file + s.config.Marker)
+	}
+
+	if err := writeResult(result); err != nil {
+		log.WithFields(log.Fields{
+			"event":  "watch_write_result_failed",
+			"file":   file,
+			"reason": err,
+		}).Error("could not write the result file")
+	}
+
+	log.WithFields(log.Fields{
+		"event":    "watch_file_processed",
+		"file":     file,
+		"status":   result.Status,
+		"moved_to": result.MovedTo,
+	}).Info()
+
+	return result
+}
+
+// move moves the file to the dir, adding the timestamp to its name when the dir has a file with the same name.
+func move(file, dir, timestamp string) (string, error) {
+	name := filepath.Base(file)
+	target := filepath.Join(dir, name)
+	if _, err := os.Stat(target); err == nil {
+		ext := filepath.Ext(name)
+		target = filepath.Join(dir, strings.TrimSuffix(name, ext)+"-"+timestamp+ext)
+	}
+
+	if err := os.Rename(file, target); err != nil {
+		return "", err
+	}
+
+	return target, nil
+}
+
+func writeResult(result *Result) error {
+	if err := os.MkdirAll(result.ResultsDir, 0755); err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(result, "", " ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(result.ResultsDir, resultFile), b, 0644)
+}
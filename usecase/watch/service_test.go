@@ -0,0 +1,184 @@
+package watch_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vsantosalmeida/csv-parser/usecase/csv"
+	"github.com/vsantosalmeida/csv-parser/usecase/watch"
+)
+
+const (
+	validRoster = "Name,Email,Wage,Number\nJohn Doe,doe@test.com,$10.00,1\nMary Jane,marytest.com,$15,2\n"
+	otherRoster = "ID,Name\n1,John Doe\n"
+)
+
+var givenPatterns = map[string]*csv.FilePattern{
+	"roster*.csv": {
+		FirstNameColumn: "Name",
+		SalaryColumn:    "Wage",
+		EmailColumn:     "Email",
+		IDColumn:        "Number",
+	},
+}
+
+func newService(t *testing.T, marker string, stableFor time.Duration) (*watch.Service, watch.Config) {
+	root, err := ioutil.TempDir("", "watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(root) })
+
+	config := watch.Config{
+		Inbox:     filepath.Join(root, "inbox"),
+		Processed: filepath.Join(root, "processed"),
+		Failed:    filepath.Join(root, "failed"),
+		Results:   filepath.Join(root, "results"),
+		Interval:  time.Millisecond,
+		StableFor: stableFor,
+		Marker:    marker,
+	}
+	if err := os.Mkdir(config.Inbox, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	svc, err := watch.NewService(config, func(outputDir string) (csv.Parser, error) {
+		return csv.NewParser(givenPatterns, csv.WithOutputDir(outputDir))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return svc, config
+}
+
+func writeFile(t *testing.T, path, content string) {
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestService_Poll(t *testing.T) {
+	svc, config := newService(t, "", 0)
+	writeFile(t, filepath.Join(config.Inbox, "roster1.csv"), validRoster)
+	writeFile(t, filepath.Join(config.Inbox, "other.csv"), otherRoster)
+	writeFile(t, filepath.Join(config.Inbox, "roster2.csv.part"), validRoster)
+
+	results, err := svc.Poll()
+	assert.NoError(t, err)
+	if !assert.Len(t, results, 2) {
+		return
+	}
+
+	// other.csv has no file pattern.
+	assert.Equal(t, watch.StatusFailed, results[0].Status)
+	assert.Equal(t, filepath.Join(config.Failed, "other.csv"), results[0].MovedTo)
+
+	assert.Equal(t, watch.StatusProcessed, results[1].Status)
+	assert.Equal(t, filepath.Join(config.Processed, "roster1.csv"), results[1].MovedTo)
+	assert.FileExists(t, results[1].MovedTo)
+	assert.Equal(t, 1, results[1].Summary.Employees)
+	assert.Equal(t, 1, results[1].Summary.BadLines)
+	assert.FileExists(t, results[1].Summary.EmployeesFile)
+	assert.FileExists(t, results[1].Summary.BadDataFile)
+	assert.Equal(t, results[1].ResultsDir, filepath.Dir(results[1].Summary.EmployeesFile))
+
+	b, err := ioutil.ReadFile(filepath.Join(results[1].ResultsDir, "result.json"))
+	assert.NoError(t, err)
+	var gotResult watch.Result
+	assert.NoError(t, json.Unmarshal(b, &gotResult))
+	assert.Equal(t, results[1].MovedTo, gotResult.MovedTo)
+
+	// the same file name is processed again without replacing the processed file.
+	writeFile(t, filepath.Join(config.Inbox, "roster1.csv"), validRoster)
+	results, err = svc.Poll()
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.NotEqual(t, filepath.Join(config.Processed, "roster1.csv"), results[0].MovedTo)
+		assert.FileExists(t, results[0].MovedTo)
+	}
+
+	entries, err := ioutil.ReadDir(config.Inbox)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1, "only the .part file must be kept")
+}
+
+func TestService_Poll_StableFor(t *testing.T) {
+	svc, config := newService(t, "", time.Hour)
+	writeFile(t, filepath.Join(config.Inbox, "roster1.csv"), validRoster)
+
+	results, err := svc.Poll()
+	assert.NoError(t, err)
+	assert.Empty(t, results, "the file was just found")
+	assert.FileExists(t, filepath.Join(config.Inbox, "roster1.csv"))
+}
+
+func TestService_Poll_Marker(t *testing.T) {
+	svc, config := newService(t, ".done", time.Hour)
+	writeFile(t, filepath.Join(config.Inbox, "roster1.csv"), validRoster)
+
+	results, err := svc.Poll()
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+
+	writeFile(t, filepath.Join(config.Inbox, "roster1.csv.done"), "")
+	results, err = svc.Poll()
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, watch.StatusProcessed, results[0].Status)
+	}
+	assert.NoFileExists(t, filepath.Join(config.Inbox, "roster1.csv.done"))
+}
+
+func TestService_Poll_MoveFailed(t *testing.T) {
+	svc, config := newService(t, "", 0)
+	file := filepath.Join(config.Inbox, "roster1.csv")
+	writeFile(t, file, validRoster)
+
+	// the processed folder is replaced by a file, so the processed files can not be moved into it.
+	if err := os.Remove(config.Processed); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, config.Processed, "")
+
+	results, err := svc.Poll()
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, watch.StatusFailed, results[0].Status)
+		assert.NotEmpty(t, results[0].Error)
+		assert.Empty(t, results[0].MovedTo)
+	}
+	assert.FileExists(t, file)
+
+	results, err = svc.Poll()
+	assert.NoError(t, err)
+	assert.Empty(t, results, "the file is skipped until it changes")
+
+	// a new version of the file is processed again.
+	writeFile(t, file, validRoster+"Jane Roe,roe@test.com,$12,3\n")
+	if err := os.Remove(config.Processed); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(config.Processed, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err = svc.Poll()
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, watch.StatusProcessed, results[0].Status)
+		assert.Equal(t, filepath.Join(config.Processed, "roster1.csv"), results[0].MovedTo)
+	}
+	assert.NoFileExists(t, file)
+}
+
+func TestNewService_InvalidConfig(t *testing.T) {
+	svc, err := watch.NewService(watch.Config{Inbox: "not_found", Interval: time.Second}, nil)
+	assert.Nil(t, svc)
+	assert.Error(t, err)
+}